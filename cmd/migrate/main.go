@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/configs"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database/migrations"
+	"log"
+	"os"
+	"strconv"
+)
+
+const usage = "usage: migrate up|down [steps]|status"
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	config, err := configs.LoadConfig(".")
+	if err != nil {
+		log.Fatalf("Error loading configs: %v", err)
+	}
+
+	db, err := database.NewConnection(config)
+	if err != nil {
+		log.Fatalf("Error to loading database connection: %v", err)
+	}
+
+	migrator, err := migrations.NewMigrator(db, migrations.All())
+	if err != nil {
+		log.Fatalf("Error loading migrations: %v", err)
+	}
+
+	switch os.Args[1] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			log.Printf("Applied %d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("Error applying migrations: %v", err)
+		}
+		if len(applied) == 0 {
+			log.Println("No pending migrations")
+		}
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+			if err != nil || steps < 1 {
+				log.Fatal(usage)
+			}
+		}
+		rolledBack, err := migrator.Down(steps)
+		for _, migration := range rolledBack {
+			log.Printf("Rolled back %d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("Error rolling back migrations: %v", err)
+		}
+		if len(rolledBack) == 0 {
+			log.Println("No applied migrations")
+		}
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatalf("Error reading migrations status: %v", err)
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+	default:
+		log.Fatal(usage)
+	}
+}
@@ -1,18 +1,28 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"github.com/SchunckLeonardo/go-expert-api/configs"
 	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
 	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database/migrations"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/jobs"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/logging"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/storage"
 	"github.com/SchunckLeonardo/go-expert-api/internal/infra/webserver/handlers"
 	"github.com/SchunckLeonardo/go-expert-api/internal/infra/webserver/middlewares"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/jwtauth"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	_ "github.com/SchunckLeonardo/go-expert-api/docs"
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -36,42 +46,259 @@ func main() {
 		log.Fatalf("Error loading configs: %v", err)
 	}
 
-	db, err := gorm.Open(sqlite.Open("test.db"), &gorm.Config{})
+	logger, err := logging.New(os.Stdout, config.LogLevel)
 	if err != nil {
-		log.Fatalf("Error to loading database connection: %v", err)
+		log.Fatalf("Error loading logger: %v", err)
 	}
+	slog.SetDefault(logger)
 
-	_ = db.AutoMigrate(&entity.Product{}, &entity.User{})
+	db, err := database.NewConnection(config)
+	if err != nil {
+		fatal("loading database connection", err)
+	}
+
+	migrator, err := migrations.NewMigrator(db, migrations.All())
+	if err != nil {
+		fatal("loading migrations", err)
+	}
+	pending, err := migrator.Pending()
+	if err != nil {
+		fatal("checking migrations", err)
+	}
+	if len(pending) > 0 {
+		slog.Error("there are pending migrations, run `go run ./cmd/migrate up` first", "pending", len(pending))
+		os.Exit(1)
+	}
 
 	productDb := database.NewProduct(db)
-	productHandler := handlers.NewProductHandler(productDb)
+	exchangeRateDb := database.NewExchangeRate(db)
+	auditHandler := handlers.NewAuditHandler(database.NewAudit(db))
+	productHandler := handlers.NewProductHandler(productDb, exchangeRateDb)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(exchangeRateDb)
+	categoryDb := database.NewCategory(db)
+	categoryHandler := handlers.NewCategoryHandler(categoryDb, productDb)
+	tagHandler := handlers.NewTagHandler(database.NewTag(db))
+	inventoryDb := database.NewInventory(db)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryDb, productDb)
+
+	variantHandler := handlers.NewVariantHandler(database.NewVariant(db), productDb)
+	priceDb := database.NewPrice(db)
+	priceHandler := handlers.NewPriceHandler(priceDb, productDb)
+
+	imageStorage, err := storage.NewLocal(config.StorageDir, config.StorageBaseURL)
+	if err != nil {
+		fatal("loading image storage", err)
+	}
+	imageHandler := handlers.NewImageHandler(database.NewImage(db), productDb, imageStorage, config.ImagesMaxSize)
 
 	userDb := database.NewUser(db)
-	userHandler := handlers.NewUserHandler(userDb, config.TokenAuth, config.JWTExpiresIn)
+	refreshTokenDb := database.NewRefreshToken(db)
+	revokedTokenDb := database.NewRevokedToken(db)
+	userHandler := handlers.NewUserHandler(
+		userDb,
+		refreshTokenDb,
+		revokedTokenDb,
+		config.TokenAuth,
+		config.JWTExpiresIn,
+		config.JWTRefreshExpiresIn,
+	)
 
 	r := chi.NewRouter()
 
-	r.Use(middlewares.LogRequest)
+	r.Use(middlewares.RequestID)
+	r.Use(middlewares.LogRequest(logger))
 	r.Use(middleware.Recoverer)
 
+	authenticated := chi.Chain(
+		jwtauth.Verifier(config.TokenAuth),
+		jwtauth.Authenticator,
+		middlewares.RejectRevokedTokens(revokedTokenDb),
+		middlewares.IdentifyActor,
+	)
+
 	r.Route("/products", func(r chi.Router) {
-		r.Use(jwtauth.Verifier(config.TokenAuth))
-		r.Use(jwtauth.Authenticator)
-
-		r.Post("/", productHandler.CreateProduct)
-		r.Get("/", productHandler.FetchProducts)
-		r.Get("/{id}", productHandler.GetProduct)
-		r.Put("/{id}", productHandler.UpdateProduct)
-		r.Delete("/{id}", productHandler.DeleteProduct)
+		r.Use(authenticated...)
+
+		r.With(middlewares.RequirePermission(entity.PermissionWriteProducts)).Post("/", productHandler.CreateProduct)
+		r.With(middlewares.RequirePermission(entity.PermissionReadProducts)).Get("/", productHandler.FetchProducts)
+		r.With(middlewares.RequirePermission(entity.PermissionWriteProducts)).Post("/bulk", productHandler.BulkCreateProducts)
+		r.With(middlewares.RequirePermission(entity.PermissionWriteProducts)).Put("/bulk", productHandler.BulkUpdateProducts)
+		r.With(middlewares.RequirePermission(entity.PermissionDeleteProducts)).Delete("/bulk", productHandler.BulkDeleteProducts)
+		r.With(middlewares.RequirePermission(entity.PermissionReadProducts)).Get("/export", productHandler.ExportProducts)
+		r.With(middlewares.RequirePermission(entity.PermissionWriteProducts)).Post("/import", productHandler.ImportProducts)
+		r.With(middlewares.RequirePermission(entity.PermissionReadProducts)).Get("/{id}", productHandler.GetProduct)
+		r.With(middlewares.RequirePermission(entity.PermissionWriteProducts)).Put("/{id}", productHandler.UpdateProduct)
+		r.With(middlewares.RequirePermission(entity.PermissionWriteProducts)).Patch("/{id}", productHandler.PatchProduct)
+		r.With(middlewares.RequirePermission(entity.PermissionDeleteProducts)).Delete("/{id}", productHandler.DeleteProduct)
+		r.With(middlewares.RequirePermission(entity.PermissionDeleteProducts)).Post("/{id}/restore", productHandler.RestoreProduct)
+		r.With(middlewares.RequirePermission(entity.PermissionReadProducts)).Get("/{id}/categories", categoryHandler.GetProductCategories)
+		r.With(middlewares.RequirePermission(entity.PermissionWriteProducts)).Put("/{id}/categories", categoryHandler.SetProductCategories)
+		r.With(middlewares.RequirePermission(entity.PermissionWriteProducts)).Post("/{id}/images", imageHandler.UploadProductImage)
+		r.With(middlewares.RequirePermission(entity.PermissionWriteProducts)).Delete("/{id}/images/{image_id}", imageHandler.DeleteProductImage)
+		r.With(middlewares.RequirePermission(entity.PermissionReadProducts)).Get("/{id}/variants", variantHandler.ListVariants)
+		r.With(middlewares.RequirePermission(entity.PermissionWriteProducts)).Post("/{id}/variants", variantHandler.CreateVariant)
+		r.With(middlewares.RequirePermission(entity.PermissionReadProducts)).Get("/{id}/variants/{variant_id}", variantHandler.GetVariant)
+		r.With(middlewares.RequirePermission(entity.PermissionWriteProducts)).Put("/{id}/variants/{variant_id}", variantHandler.UpdateVariant)
+		r.With(middlewares.RequirePermission(entity.PermissionWriteProducts)).Delete("/{id}/variants/{variant_id}", variantHandler.DeleteVariant)
+		r.With(middlewares.RequirePermission(entity.PermissionReadProducts)).Get("/{id}/prices", priceHandler.ListPriceHistory)
+		r.With(middlewares.RequirePermission(entity.PermissionReadProducts)).Get("/{id}/prices/scheduled", priceHandler.ListScheduledPrices)
+		r.With(middlewares.RequirePermission(entity.PermissionWriteProducts)).Post("/{id}/prices/scheduled", priceHandler.SchedulePrice)
+		r.With(middlewares.RequirePermission(entity.PermissionWriteProducts)).Post("/{id}/prices/scheduled/{schedule_id}/cancel", priceHandler.CancelScheduledPrice)
+		r.With(middlewares.RequirePermission(entity.PermissionReadProducts)).Get("/{id}/inventory", inventoryHandler.GetInventory)
+		r.With(middlewares.RequirePermission(entity.PermissionReadProducts)).Get("/{id}/inventory/adjustments", inventoryHandler.ListStockAdjustments)
+		r.With(middlewares.RequirePermission(entity.PermissionManageInventory)).Post("/{id}/inventory/adjustments", inventoryHandler.AdjustStock)
+	})
+
+	r.Route("/categories", func(r chi.Router) {
+		r.Use(authenticated...)
+
+		r.With(middlewares.RequirePermission(entity.PermissionReadProducts)).Get("/", categoryHandler.ListCategories)
+		r.With(middlewares.RequirePermission(entity.PermissionReadProducts)).Get("/tree", categoryHandler.GetCategoryTree)
+		r.With(middlewares.RequirePermission(entity.PermissionReadProducts)).Get("/{id}", categoryHandler.GetCategory)
+		r.With(middlewares.RequirePermission(entity.PermissionManageCategories)).Post("/", categoryHandler.CreateCategory)
+		r.With(middlewares.RequirePermission(entity.PermissionManageCategories)).Put("/{id}", categoryHandler.UpdateCategory)
+		r.With(middlewares.RequirePermission(entity.PermissionManageCategories)).Delete("/{id}", categoryHandler.DeleteCategory)
+	})
+
+	r.Route("/exchange-rates", func(r chi.Router) {
+		r.Use(authenticated...)
+
+		r.With(middlewares.RequirePermission(entity.PermissionReadProducts)).Get("/", exchangeRateHandler.ListExchangeRates)
+		r.With(middlewares.RequirePermission(entity.PermissionReadProducts)).Get("/{currency}", exchangeRateHandler.GetExchangeRate)
+		r.With(middlewares.RequirePermission(entity.PermissionManageExchangeRates)).Post("/", exchangeRateHandler.CreateExchangeRate)
+		r.With(middlewares.RequirePermission(entity.PermissionManageExchangeRates)).Put("/{currency}", exchangeRateHandler.UpdateExchangeRate)
+		r.With(middlewares.RequirePermission(entity.PermissionManageExchangeRates)).Delete("/{currency}", exchangeRateHandler.DeleteExchangeRate)
 	})
 
+	r.Route("/reservations", func(r chi.Router) {
+		r.Use(authenticated...)
+		r.Use(middlewares.RequirePermission(entity.PermissionManageInventory))
+
+		r.Post("/", inventoryHandler.ReserveStock)
+		r.Get("/{id}", inventoryHandler.GetReservation)
+		r.Post("/{id}/commit", inventoryHandler.CommitReservation)
+		r.Post("/{id}/release", inventoryHandler.ReleaseReservation)
+	})
+
+	r.With(authenticated...).
+		With(middlewares.RequirePermission(entity.PermissionReadProducts)).
+		Get("/tags", tagHandler.ListTags)
+
 	// User
 	r.Post("/users", userHandler.Create)
+	r.With(authenticated...).
+		With(middlewares.RequirePermission(entity.PermissionManageUsers)).
+		Put("/users/{id}/role", userHandler.UpdateRole)
 	r.Post("/sessions", userHandler.GetJWT)
+	r.Post("/sessions/refresh", userHandler.RefreshJWT)
+	r.With(authenticated...).Delete("/sessions", userHandler.Logout)
+
+	r.With(authenticated...).
+		With(middlewares.RequirePermission(entity.PermissionReadAudit)).
+		Get("/audit", auditHandler.ListAuditEntries)
+
+	r.Handle("/images/*", http.StripPrefix("/images/", imageStorage.Handler()))
 
 	r.Get("/docs/*", httpSwagger.Handler(httpSwagger.URL("http://localhost:8080/docs/doc.json")))
 
-	httpAddress := config.WebServerHost + ":" + config.WebServerPort
-	log.Println("Server running on " + httpAddress)
-	_ = http.ListenAndServe(httpAddress, r)
+	server := &http.Server{
+		Addr:         config.WebServerHost + ":" + config.WebServerPort,
+		Handler:      r,
+		ReadTimeout:  time.Duration(config.WebServerReadTimeout) * time.Second,
+		WriteTimeout: time.Duration(config.WebServerWriteTimeout) * time.Second,
+		IdleTimeout:  time.Duration(config.WebServerIdleTimeout) * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var backgroundJobs sync.WaitGroup
+	backgroundJobs.Add(1)
+	go func() {
+		defer backgroundJobs.Done()
+		jobs.Every(ctx, time.Duration(config.RevokedTokensPruneEvery)*time.Second, "prune revoked tokens", func(ctx context.Context) error {
+			_, err := revokedTokenDb.DeleteExpired(ctx, time.Now())
+			return err
+		})
+	}()
+	backgroundJobs.Add(1)
+	go func() {
+		defer backgroundJobs.Done()
+		jobs.Every(ctx, time.Duration(config.DeletedProductsPurgeEvery)*time.Second, "purge deleted products", func(ctx context.Context) error {
+			purged, err := productDb.PurgeDeleted(ctx, time.Now().AddDate(0, 0, -config.DeletedProductsRetention))
+			if purged > 0 {
+				slog.InfoContext(ctx, "purged deleted products", "purged", purged)
+			}
+			if err != nil {
+				return err
+			}
+			purgedImages, err := imageHandler.PurgeOrphanedImages(ctx)
+			if purgedImages > 0 {
+				slog.InfoContext(ctx, "purged images of deleted products", "purged", purgedImages)
+			}
+			return err
+		})
+	}()
+	backgroundJobs.Add(1)
+	go func() {
+		defer backgroundJobs.Done()
+		jobs.Every(ctx, time.Duration(config.ReservationsReleaseEvery)*time.Second, "release expired reservations", func(ctx context.Context) error {
+			released, err := inventoryDb.ReleaseExpired(ctx, time.Now())
+			if released > 0 {
+				slog.InfoContext(ctx, "released expired reservations", "released", released)
+			}
+			return err
+		})
+	}()
+	backgroundJobs.Add(1)
+	go func() {
+		defer backgroundJobs.Done()
+		jobs.Every(ctx, time.Duration(config.ScheduledPricesApplyEvery)*time.Second, "apply scheduled prices", func(ctx context.Context) error {
+			applied, err := priceDb.ApplyScheduled(ctx, time.Now())
+			if applied > 0 {
+				slog.InfoContext(ctx, "applied scheduled prices", "applied", applied)
+			}
+			return err
+		})
+	}()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("server running", "addr", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err = <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			fatal("running server", err)
+		}
+	case <-ctx.Done():
+		stop()
+		slog.Info("shutting down server")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(config.WebServerShutdownTimeout)*time.Second)
+		defer cancel()
+		if err = server.Shutdown(shutdownCtx); err != nil {
+			slog.Error("shutting down server", "error", err)
+		}
+	}
+
+	stop()
+	backgroundJobs.Wait()
+
+	sqlDB, err := db.DB()
+	if err == nil {
+		err = sqlDB.Close()
+	}
+	if err != nil {
+		slog.Error("closing database connection", "error", err)
+	}
+	slog.Info("server stopped")
+}
+
+// fatal logs err and exits, as the server can't run without what failed.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
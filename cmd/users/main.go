@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"github.com/SchunckLeonardo/go-expert-api/configs"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	"log"
+	"os"
+)
+
+const usage = "usage: users set-role <email> admin|editor|viewer"
+
+// users manages accounts outside the API, e.g. to promote the first admin.
+func main() {
+	if len(os.Args) != 4 || os.Args[1] != "set-role" {
+		log.Fatal(usage)
+	}
+
+	config, err := configs.LoadConfig(".")
+	if err != nil {
+		log.Fatalf("Error loading configs: %v", err)
+	}
+
+	db, err := database.NewConnection(config)
+	if err != nil {
+		log.Fatalf("Error to loading database connection: %v", err)
+	}
+
+	userDb := database.NewUser(db)
+	user, err := userDb.FindByEmail(context.Background(), os.Args[2])
+	if err != nil {
+		log.Fatalf("Error finding user: %v", err)
+	}
+
+	if err = user.SetRole(entity.Role(os.Args[3])); err != nil {
+		log.Fatal(usage)
+	}
+
+	if err = userDb.Update(context.Background(), user); err != nil {
+		log.Fatalf("Error updating user: %v", err)
+	}
+
+	log.Printf("User %s is now %s", user.Email, user.Role)
+}
@@ -1,6 +1,7 @@
 package configs
 
 import (
+	"fmt"
 	"github.com/go-chi/jwtauth"
 	"github.com/spf13/viper"
 )
@@ -14,11 +15,34 @@ type Conf struct {
 	DBUser        string `mapstructure:"DB_USER"`
 	DBPassword    string `mapstructure:"DB_PASSWORD"`
 	DBDatabase    string `mapstructure:"DB_DATABASE"`
+	DBSSLMode     string `mapstructure:"DB_SSL_MODE"`
 	WebServerHost string `mapstructure:"WEB_SERVER_HOST"`
 	WebServerPort string `mapstructure:"WEB_SERVER_PORT"`
-	JWTSecret     string `mapstructure:"JWT_SECRET"`
-	JWTExpiresIn  int    `mapstructure:"JWT_EXPIRES_IN"`
-	TokenAuth     *jwtauth.JWTAuth
+	// Web server timeouts, in seconds
+	WebServerReadTimeout     int    `mapstructure:"WEB_SERVER_READ_TIMEOUT"`
+	WebServerWriteTimeout    int    `mapstructure:"WEB_SERVER_WRITE_TIMEOUT"`
+	WebServerIdleTimeout     int    `mapstructure:"WEB_SERVER_IDLE_TIMEOUT"`
+	WebServerShutdownTimeout int    `mapstructure:"WEB_SERVER_SHUTDOWN_TIMEOUT"`
+	JWTSecret                string `mapstructure:"JWT_SECRET"`
+	JWTExpiresIn             int    `mapstructure:"JWT_EXPIRES_IN"`
+	JWTRefreshExpiresIn      int    `mapstructure:"JWT_REFRESH_EXPIRES_IN"`
+	RevokedTokensPruneEvery  int    `mapstructure:"REVOKED_TOKENS_PRUNE_EVERY"`
+	// Deleted products are purged once older than the retention, in days
+	DeletedProductsRetention  int `mapstructure:"DELETED_PRODUCTS_RETENTION"`
+	DeletedProductsPurgeEvery int `mapstructure:"DELETED_PRODUCTS_PURGE_EVERY"`
+	// Expired stock reservations give their units back this often, in seconds
+	ReservationsReleaseEvery int `mapstructure:"RESERVATIONS_RELEASE_EVERY"`
+	// Scheduled prices start and end this often, in seconds
+	ScheduledPricesApplyEvery int `mapstructure:"SCHEDULED_PRICES_APPLY_EVERY"`
+	// Product images are stored below StorageDir and downloaded from StorageBaseURL,
+	// which the server answers under /images unless it points elsewhere
+	StorageDir     string `mapstructure:"STORAGE_DIR"`
+	StorageBaseURL string `mapstructure:"STORAGE_BASE_URL"`
+	// Largest image accepted, in bytes
+	ImagesMaxSize int64 `mapstructure:"IMAGES_MAX_SIZE"`
+	// Lowest level logged, one of debug, info, warn or error
+	LogLevel  string `mapstructure:"LOG_LEVEL"`
+	TokenAuth *jwtauth.JWTAuth
 }
 
 func LoadConfig(path string) (*Conf, error) {
@@ -27,6 +51,20 @@ func LoadConfig(path string) (*Conf, error) {
 	viper.AddConfigPath(path)
 	viper.SetConfigFile(".env")
 	viper.AutomaticEnv()
+	viper.SetDefault("WEB_SERVER_READ_TIMEOUT", 15)
+	viper.SetDefault("WEB_SERVER_WRITE_TIMEOUT", 15)
+	viper.SetDefault("WEB_SERVER_IDLE_TIMEOUT", 60)
+	viper.SetDefault("WEB_SERVER_SHUTDOWN_TIMEOUT", 30)
+	viper.SetDefault("JWT_REFRESH_EXPIRES_IN", 60*60*24*30)
+	viper.SetDefault("REVOKED_TOKENS_PRUNE_EVERY", 60*60)
+	viper.SetDefault("DELETED_PRODUCTS_RETENTION", 30)
+	viper.SetDefault("DELETED_PRODUCTS_PURGE_EVERY", 60*60)
+	viper.SetDefault("RESERVATIONS_RELEASE_EVERY", 60)
+	viper.SetDefault("SCHEDULED_PRICES_APPLY_EVERY", 60)
+	viper.SetDefault("STORAGE_DIR", "uploads")
+	viper.SetDefault("STORAGE_BASE_URL", "http://localhost:8080/images")
+	viper.SetDefault("IMAGES_MAX_SIZE", 5<<20)
+	viper.SetDefault("LOG_LEVEL", "info")
 	if err := viper.ReadInConfig(); err != nil {
 		return nil, err
 	}
@@ -35,7 +73,36 @@ func LoadConfig(path string) (*Conf, error) {
 		return nil, err
 	}
 
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
 	cfg.TokenAuth = jwtauth.New("HS256", []byte(cfg.JWTSecret), nil)
 
 	return cfg, nil
 }
+
+// validate checks that the intervals and timeouts are positive, as the jobs
+// can't run every 0 seconds.
+func (c *Conf) validate() error {
+	durations := []struct {
+		name  string
+		value int
+	}{
+		{"WEB_SERVER_READ_TIMEOUT", c.WebServerReadTimeout},
+		{"WEB_SERVER_WRITE_TIMEOUT", c.WebServerWriteTimeout},
+		{"WEB_SERVER_IDLE_TIMEOUT", c.WebServerIdleTimeout},
+		{"WEB_SERVER_SHUTDOWN_TIMEOUT", c.WebServerShutdownTimeout},
+		{"REVOKED_TOKENS_PRUNE_EVERY", c.RevokedTokensPruneEvery},
+		{"DELETED_PRODUCTS_RETENTION", c.DeletedProductsRetention},
+		{"DELETED_PRODUCTS_PURGE_EVERY", c.DeletedProductsPurgeEvery},
+		{"RESERVATIONS_RELEASE_EVERY", c.ReservationsReleaseEvery},
+		{"SCHEDULED_PRICES_APPLY_EVERY", c.ScheduledPricesApplyEvery},
+	}
+	for _, duration := range durations {
+		if duration.value <= 0 {
+			return fmt.Errorf("%s must be greater than 0, got %d", duration.name, duration.value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package configs
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func validConf() Conf {
+	return Conf{
+		WebServerReadTimeout:      15,
+		WebServerWriteTimeout:     15,
+		WebServerIdleTimeout:      60,
+		WebServerShutdownTimeout:  30,
+		RevokedTokensPruneEvery:   3600,
+		DeletedProductsRetention:  30,
+		DeletedProductsPurgeEvery: 3600,
+		ReservationsReleaseEvery:  60,
+		ScheduledPricesApplyEvery: 60,
+	}
+}
+
+func TestConf_Validate(t *testing.T) {
+	conf := validConf()
+	assert.Nil(t, conf.validate())
+
+	conf.RevokedTokensPruneEvery = 0
+	assert.ErrorContains(t, conf.validate(), "REVOKED_TOKENS_PRUNE_EVERY")
+
+	conf = validConf()
+	conf.WebServerShutdownTimeout = -1
+	assert.ErrorContains(t, conf.validate(), "WEB_SERVER_SHUTDOWN_TIMEOUT")
+}
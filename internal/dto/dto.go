@@ -1,11 +1,18 @@
 package dto
 
-import "github.com/SchunckLeonardo/go-expert-api/internal/entity"
+import (
+	"encoding/json"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"time"
+)
 
 type CreateProductInput struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Price in USD when no currency is given
+	Price entity2.Money `json:"price"`
+	Tags  []string      `json:"tags" example:"new,clearance"`
 }
 
 type CreateUserInput struct {
@@ -14,23 +21,145 @@ type CreateUserInput struct {
 	Password string `json:"password"`
 }
 
+type UpdateUserRoleInput struct {
+	Role string `json:"role" enums:"admin,editor,viewer"`
+}
+
 type GetJWTInput struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
 
 type GetJWTOutput struct {
-	AccessToken string `json:"acess_token"`
+	AccessToken  string `json:"acess_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshJWTInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type LogoutInput struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 type FetchProductsOutput struct {
 	Products    []entity.Product
 	ItemsAmount int
 	TotalPages  int
+	// Opaque cursors for the next and previous pages, only set in cursor mode
+	NextCursor string `json:",omitempty"`
+	PrevCursor string `json:",omitempty"`
 }
 
 type UpdateProductInput struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Price in USD when no currency is given
+	Price entity2.Money `json:"price"`
+	// Replace the tags when sent, an empty list removes them
+	Tags []string `json:"tags" example:"new,clearance"`
+}
+
+type BulkUpdateProductInput struct {
+	ID string `json:"id"`
+	// Version the product is expected to have, like the If-Match of PUT /products/{id}
+	Version int `json:"version"`
+	UpdateProductInput
+}
+
+type BulkDeleteProductInput struct {
+	ID      string `json:"id"`
+	Version int    `json:"version"`
+}
+
+type BulkItemResult struct {
+	Index   int    `json:"index"`
+	ID      string `json:"id,omitempty"`
+	Version int    `json:"version,omitempty"`
+	Status  int    `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+type BulkOutput struct {
+	Results   []BulkItemResult `json:"results"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+}
+
+type ImportRowError struct {
+	// Line of the file where the product is, counting the CSV header
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type ImportProductsOutput struct {
+	Imported int              `json:"imported"`
+	Errors   []ImportRowError `json:"errors,omitempty"`
+}
+
+type CreateExchangeRateInput struct {
+	Currency string `json:"currency" example:"EUR"`
+	UpdateExchangeRateInput
+}
+
+type UpdateExchangeRateInput struct {
+	// Units of the currency bought by one USD
+	Rate json.Number `json:"rate" swaggertype:"string" example:"0.92"`
+	// half_up when empty
+	RoundingMode string `json:"rounding_mode" enums:"half_up,half_even,up,down"`
+	// Converted amounts are rounded to a multiple of this many minor units, 1 when empty
+	RoundingIncrement int64 `json:"rounding_increment"`
+}
+
+type CreateCategoryInput struct {
+	Name string `json:"name"`
+	// Category this one is below, a root category when empty
+	ParentID *string `json:"parent_id"`
+}
+
+type UpdateCategoryInput struct {
+	Name     string  `json:"name"`
+	ParentID *string `json:"parent_id"`
+}
+
+type SetProductCategoriesInput struct {
+	CategoryIDs []string `json:"category_ids"`
+}
+
+type CategoryTreeNode struct {
+	entity.Category
+	Children []*CategoryTreeNode `json:"children"`
+}
+
+type AdjustStockInput struct {
+	// Units added on hand, negative to remove them
+	Delta  int64  `json:"delta" example:"10"`
+	Reason string `json:"reason" enums:"restock,sale,return,damage,correction"`
+	Note   string `json:"note"`
+}
+
+type ReserveStockItemInput struct {
+	ProductID string `json:"product_id"`
+	Quantity  int64  `json:"quantity" example:"1"`
+}
+
+type ReserveStockInput struct {
+	Items []ReserveStockItemInput `json:"items"`
+	// Seconds until the reservation expires, 900 when empty
+	TTLSeconds int64 `json:"ttl_seconds" example:"900"`
+}
+
+type VariantInput struct {
+	SKU     string            `json:"sku" example:"SHIRT-M-BLUE"`
+	Options map[string]string `json:"options"`
+	// Overrides the price of the product, which the variant is sold for when empty
+	Price *entity2.Money `json:"price"`
+}
+
+type SchedulePriceInput struct {
+	Price    entity2.Money `json:"price"`
+	StartsAt time.Time     `json:"starts_at" example:"2026-11-27T00:00:00Z"`
+	// The product gets its previous price back at ends_at, and keeps the scheduled one when empty
+	EndsAt *time.Time `json:"ends_at" example:"2026-11-30T00:00:00Z"`
 }
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"encoding/json"
+	"github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+)
+
+func (a AuditAction) IsValid() bool {
+	switch a {
+	case AuditCreate, AuditUpdate, AuditDelete, AuditRestore:
+		return true
+	}
+	return false
+}
+
+type AuditResource string
+
+const (
+	AuditProduct AuditResource = "product"
+	AuditUser    AuditResource = "user"
+	// AuditProductCategories is the set of categories of a product, identified by the product ID
+	AuditProductCategories AuditResource = "product_categories"
+)
+
+func (r AuditResource) IsValid() bool {
+	return r == AuditProduct || r == AuditUser || r == AuditProductCategories
+}
+
+// AuditEntry records a change made through the API: who made it, to what,
+// and the resource as it was before and after it.
+type AuditEntry struct {
+	ID entity.ID `json:"id"`
+	// ActorID is the user who made the change, empty for requests without a token like sign ups
+	// and for the changes made by the server itself, like applying scheduled prices
+	ActorID      string        `json:"actor_id"`
+	Action       AuditAction   `json:"action" example:"delete"`
+	ResourceType AuditResource `json:"resource_type" example:"product"`
+	ResourceID   string        `json:"resource_id"`
+	// Before is null for a created resource
+	Before json.RawMessage `json:"before" gorm:"serializer:json" swaggertype:"object"`
+	// After is null for a deleted resource
+	After     json.RawMessage `json:"after" gorm:"serializer:json" swaggertype:"object"`
+	RequestID string          `json:"request_id"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func (AuditEntry) TableName() string { return "audit_log" }
+
+// NewAuditEntry returns the entry of a change, where before and after are the
+// JSON snapshots of the resource, or nil when it didn't exist.
+func NewAuditEntry(actorID string, action AuditAction, resourceType AuditResource, resourceID string, before, after json.RawMessage, requestID string) *AuditEntry {
+	return &AuditEntry{
+		ID:           entity.NewID(),
+		ActorID:      actorID,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Before:       before,
+		After:        after,
+		RequestID:    requestID,
+		CreatedAt:    time.Now(),
+	}
+}
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewAuditEntry(t *testing.T) {
+	after := json.RawMessage(`{"name":"Laptop"}`)
+	entry := NewAuditEntry("user", AuditCreate, AuditProduct, "1", nil, after, "request")
+	assert.NotEmpty(t, entry.ID)
+	assert.Equal(t, AuditCreate, entry.Action)
+	assert.Equal(t, AuditProduct, entry.ResourceType)
+	assert.Nil(t, entry.Before)
+	assert.JSONEq(t, `{"name":"Laptop"}`, string(entry.After))
+	assert.Equal(t, "request", entry.RequestID)
+	assert.False(t, entry.CreatedAt.IsZero())
+}
+
+func TestAuditAction_IsValid(t *testing.T) {
+	assert.True(t, AuditDelete.IsValid())
+	assert.False(t, AuditAction("read").IsValid())
+	assert.True(t, AuditUser.IsValid())
+	assert.True(t, AuditProductCategories.IsValid())
+	assert.False(t, AuditResource("category").IsValid())
+}
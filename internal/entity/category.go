@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"errors"
+	"github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"time"
+)
+
+var (
+	ErrCategoryNameIsRequired = errors.New("category name is required")
+	ErrCategoryCycle          = errors.New("a category can't be its own parent or descend from itself")
+)
+
+// Category groups products in a hierarchy, where root categories have no parent.
+type Category struct {
+	ID        entity.ID  `json:"id"`
+	Name      string     `json:"name"`
+	ParentID  *entity.ID `json:"parent_id"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func NewCategory(name string, parentID *entity.ID) (*Category, error) {
+	category := &Category{
+		ID:        entity.NewID(),
+		Name:      name,
+		ParentID:  parentID,
+		CreatedAt: time.Now(),
+	}
+	if err := category.Validate(); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (c *Category) Validate() error {
+	if c.Name == "" {
+		return ErrCategoryNameIsRequired
+	}
+	if c.ParentID != nil && *c.ParentID == c.ID {
+		return ErrCategoryCycle
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewCategory(t *testing.T) {
+	root, err := NewCategory("Electronics", nil)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, root.ID)
+	assert.Nil(t, root.ParentID)
+
+	child, err := NewCategory("Laptops", &root.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, root.ID, *child.ParentID)
+
+	_, err = NewCategory("", nil)
+	assert.Equal(t, ErrCategoryNameIsRequired, err)
+}
+
+func TestCategory_ValidateOwnParent(t *testing.T) {
+	category, err := NewCategory("Electronics", nil)
+	assert.Nil(t, err)
+
+	category.ParentID = &category.ID
+	assert.Equal(t, ErrCategoryCycle, category.Validate())
+}
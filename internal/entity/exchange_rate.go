@@ -0,0 +1,143 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BaseCurrency is the currency exchange rates are quoted against.
+const BaseCurrency = entity.DefaultCurrency
+
+var (
+	ErrInvalidRate      = errors.New("rate must be a positive decimal number")
+	ErrBaseCurrencyRate = errors.New("the base currency " + BaseCurrency + " can't have an exchange rate")
+	ErrNoExchangeRate   = errors.New("no exchange rate")
+)
+
+var decimalRate = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// ExchangeRate is how many units of Currency one unit of BaseCurrency buys,
+// with how amounts converted to Currency are rounded.
+type ExchangeRate struct {
+	Currency          string              `json:"currency" gorm:"primaryKey"`
+	Rate              string              `json:"rate" example:"0.92"`
+	RoundingMode      entity.RoundingMode `json:"rounding_mode" enums:"half_up,half_even,up,down"`
+	RoundingIncrement int64               `json:"rounding_increment" example:"1"`
+	UpdatedAt         time.Time           `json:"updated_at"`
+}
+
+func NewExchangeRate(currency, rate string, rounding entity.Rounding) (*ExchangeRate, error) {
+	exchangeRate := &ExchangeRate{
+		Currency:          strings.ToUpper(currency),
+		Rate:              rate,
+		RoundingMode:      rounding.Mode,
+		RoundingIncrement: rounding.Increment,
+		UpdatedAt:         time.Now(),
+	}
+	if err := exchangeRate.Validate(); err != nil {
+		return nil, err
+	}
+	return exchangeRate, nil
+}
+
+func (e *ExchangeRate) Validate() error {
+	if e.Currency == BaseCurrency {
+		return ErrBaseCurrencyRate
+	}
+	if !entity.IsCurrency(e.Currency) {
+		return entity.ErrInvalidCurrency
+	}
+	if e.Ratio() == nil {
+		return ErrInvalidRate
+	}
+	return e.Rounding().Validate()
+}
+
+// Ratio returns the rate as an exact fraction, or nil when it isn't valid.
+func (e *ExchangeRate) Ratio() *big.Rat {
+	if !decimalRate.MatchString(e.Rate) {
+		return nil
+	}
+	ratio, ok := new(big.Rat).SetString(e.Rate)
+	if !ok || ratio.Sign() <= 0 {
+		return nil
+	}
+	return ratio
+}
+
+func (e *ExchangeRate) Rounding() entity.Rounding {
+	return entity.Rounding{Mode: e.RoundingMode, Increment: e.RoundingIncrement}
+}
+
+// CurrencyConverter converts money between BaseCurrency and the currencies
+// of a set of exchange rates, going through BaseCurrency between two of them.
+type CurrencyConverter struct {
+	rates map[string]ExchangeRate
+}
+
+func NewCurrencyConverter(rates []ExchangeRate) *CurrencyConverter {
+	converter := &CurrencyConverter{rates: make(map[string]ExchangeRate, len(rates))}
+	for _, rate := range rates {
+		converter.rates[rate.Currency] = rate
+	}
+	return converter
+}
+
+// Currencies returns BaseCurrency and every currency with a rate, sorted.
+func (c *CurrencyConverter) Currencies() []string {
+	currencies := []string{BaseCurrency}
+	for currency := range c.rates {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// Convert returns money in currency, rounded with the rounding of currency.
+// It returns ErrNoExchangeRate when either currency has no rate.
+func (c *CurrencyConverter) Convert(money entity.Money, currency string) (entity.Money, error) {
+	currency = strings.ToUpper(currency)
+	rounding := entity.DefaultRounding
+	if rate, ok := c.rates[currency]; ok {
+		rounding = rate.Rounding()
+	}
+	return c.ConvertRounded(money, currency, rounding)
+}
+
+// ConvertRounded returns money in currency, rounded with rounding.
+func (c *CurrencyConverter) ConvertRounded(money entity.Money, currency string, rounding entity.Rounding) (entity.Money, error) {
+	currency = strings.ToUpper(currency)
+	if money.Currency == currency {
+		return money, nil
+	}
+
+	from, err := c.ratio(money.Currency)
+	if err != nil {
+		return entity.Money{}, err
+	}
+	to, err := c.ratio(currency)
+	if err != nil {
+		return entity.Money{}, err
+	}
+	return money.Convert(currency, to.Quo(to, from), rounding)
+}
+
+func (c *CurrencyConverter) ratio(currency string) (*big.Rat, error) {
+	if currency == BaseCurrency {
+		return big.NewRat(1, 1), nil
+	}
+	if !entity.IsCurrency(currency) {
+		return nil, fmt.Errorf("%w: %q", entity.ErrInvalidCurrency, currency)
+	}
+	rate, ok := c.rates[currency]
+	if !ok || rate.Ratio() == nil {
+		return nil, fmt.Errorf("%w for %s", ErrNoExchangeRate, currency)
+	}
+	return rate.Ratio(), nil
+}
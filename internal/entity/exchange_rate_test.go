@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewExchangeRate(t *testing.T) {
+	rate, err := NewExchangeRate("eur", "0.92", entity.DefaultRounding)
+	assert.Nil(t, err)
+	assert.Equal(t, "EUR", rate.Currency)
+	assert.Equal(t, "23/25", rate.Ratio().String())
+
+	_, err = NewExchangeRate("USD", "1", entity.DefaultRounding)
+	assert.ErrorIs(t, err, ErrBaseCurrencyRate)
+
+	_, err = NewExchangeRate("XYZ", "1", entity.DefaultRounding)
+	assert.ErrorIs(t, err, entity.ErrInvalidCurrency)
+
+	for _, invalid := range []string{"", "0", "-1", "1/3", "1e3", "abc"} {
+		_, err = NewExchangeRate("EUR", invalid, entity.DefaultRounding)
+		assert.ErrorIs(t, err, ErrInvalidRate, invalid)
+	}
+
+	_, err = NewExchangeRate("EUR", "0.92", entity.Rounding{Mode: entity.RoundUp})
+	assert.ErrorIs(t, err, entity.ErrInvalidRounding)
+}
+
+func TestCurrencyConverter(t *testing.T) {
+	eur, _ := NewExchangeRate("EUR", "0.92", entity.DefaultRounding)
+	chf, _ := NewExchangeRate("CHF", "0.9", entity.Rounding{Mode: entity.RoundHalfUp, Increment: 5})
+	converter := NewCurrencyConverter([]ExchangeRate{*eur, *chf})
+
+	assert.Equal(t, []string{"CHF", "EUR", "USD"}, converter.Currencies())
+
+	converted, err := converter.Convert(entity.Money{Amount: 1000, Currency: "USD"}, "EUR")
+	assert.Nil(t, err)
+	assert.Equal(t, entity.Money{Amount: 920, Currency: "EUR"}, converted)
+
+	converted, err = converter.Convert(entity.Money{Amount: 920, Currency: "EUR"}, "USD")
+	assert.Nil(t, err)
+	assert.Equal(t, entity.Money{Amount: 1000, Currency: "USD"}, converted)
+
+	converted, err = converter.Convert(entity.Money{Amount: 1000, Currency: "EUR"}, "chf")
+	assert.Nil(t, err)
+	assert.Equal(t, entity.Money{Amount: 980, Currency: "CHF"}, converted)
+
+	_, err = converter.Convert(entity.Money{Amount: 1000, Currency: "USD"}, "BRL")
+	assert.ErrorIs(t, err, ErrNoExchangeRate)
+
+	_, err = converter.Convert(entity.Money{Amount: 1000, Currency: "BRL"}, "USD")
+	assert.ErrorIs(t, err, ErrNoExchangeRate)
+}
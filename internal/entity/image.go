@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"errors"
+	"github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"time"
+)
+
+var ErrInvalidImageSize = errors.New("image size and dimensions must be positive")
+
+// ProductImage is an image of a product kept in the blob storage, along with a
+// thumbnail of it.
+type ProductImage struct {
+	ID          entity.ID `json:"id"`
+	ProductID   entity.ID `json:"-"`
+	ContentType string    `json:"content_type" example:"image/jpeg"`
+	// Size of the uploaded file, in bytes
+	Size   int64 `json:"size"`
+	Width  int   `json:"width"`
+	Height int   `json:"height"`
+	// Storage keys of the image and of its thumbnail
+	Key          string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func NewProductImage(productID entity.ID, contentType string, size int64, width, height int) (*ProductImage, error) {
+	image := &ProductImage{
+		ID:          entity.NewID(),
+		ProductID:   productID,
+		ContentType: contentType,
+		Size:        size,
+		Width:       width,
+		Height:      height,
+		CreatedAt:   time.Now(),
+	}
+	if err := image.Validate(); err != nil {
+		return nil, err
+	}
+	return image, nil
+}
+
+func (i *ProductImage) Validate() error {
+	if i.ProductID.String() == "" {
+		return ErrIDIsRequired
+	}
+	if i.Size <= 0 || i.Width <= 0 || i.Height <= 0 {
+		return ErrInvalidImageSize
+	}
+	return nil
+}
+
+// StorageKey returns the key of a blob of the image, like "products/<product id>/<image id>_thumb.png"
+// for the suffix "_thumb" and the extension ".png".
+func (i *ProductImage) StorageKey(suffix, extension string) string {
+	return "products/" + i.ProductID.String() + "/" + i.ID.String() + suffix + extension
+}
@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewProductImage(t *testing.T) {
+	productID := entity.NewID()
+	image, err := NewProductImage(productID, "image/png", 1024, 640, 480)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, image.ID)
+	assert.Equal(t, productID, image.ProductID)
+	assert.Equal(t, "products/"+productID.String()+"/"+image.ID.String()+"_thumb.png", image.StorageKey("_thumb", ".png"))
+
+	_, err = NewProductImage(productID, "image/png", 0, 640, 480)
+	assert.Equal(t, ErrInvalidImageSize, err)
+	_, err = NewProductImage(productID, "image/png", 1024, 0, 480)
+	assert.Equal(t, ErrInvalidImageSize, err)
+}
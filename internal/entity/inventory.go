@@ -0,0 +1,181 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"sort"
+	"time"
+)
+
+// MaxReservationTTL is the longest a reservation can hold stock before it expires.
+const MaxReservationTTL = 24 * time.Hour
+
+var (
+	ErrInvalidQuantity       = errors.New("quantity must be positive")
+	ErrInvalidStockDelta     = errors.New("stock adjustment can't be zero")
+	ErrInvalidStockReason    = errors.New("invalid stock adjustment reason")
+	ErrInvalidReservationTTL = fmt.Errorf("reservation ttl must be between 1 second and %s", MaxReservationTTL)
+	ErrEmptyReservation      = errors.New("reservation needs at least one item")
+	ErrDuplicatedReservation = errors.New("reservation has the same product more than once")
+)
+
+// StockReason tells why the stock of a product was adjusted.
+type StockReason string
+
+const (
+	StockReasonRestock    StockReason = "restock"
+	StockReasonSale       StockReason = "sale"
+	StockReasonReturn     StockReason = "return"
+	StockReasonDamage     StockReason = "damage"
+	StockReasonCorrection StockReason = "correction"
+)
+
+func (r StockReason) IsValid() bool {
+	switch r {
+	case StockReasonRestock, StockReasonSale, StockReasonReturn, StockReasonDamage, StockReasonCorrection:
+		return true
+	}
+	return false
+}
+
+// Inventory is the stock of a product. Reserved units are still on hand but
+// can't be reserved again.
+type Inventory struct {
+	ProductID entity.ID `json:"product_id" gorm:"primaryKey"`
+	OnHand    int64     `json:"on_hand"`
+	Reserved  int64     `json:"reserved"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Available returns the units that can still be reserved.
+func (i Inventory) Available() int64 {
+	return i.OnHand - i.Reserved
+}
+
+// MarshalJSON adds the available units to the inventory.
+func (i Inventory) MarshalJSON() ([]byte, error) {
+	type inventory Inventory
+	return json.Marshal(struct {
+		inventory
+		Available int64 `json:"available"`
+	}{inventory(i), i.Available()})
+}
+
+// StockAdjustment records a change to the units on hand of a product.
+type StockAdjustment struct {
+	ID            entity.ID   `json:"id"`
+	ProductID     entity.ID   `json:"product_id"`
+	Delta         int64       `json:"delta"`
+	Reason        StockReason `json:"reason"`
+	Note          string      `json:"note"`
+	UserID        string      `json:"user_id"`
+	ReservationID *entity.ID  `json:"reservation_id"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+func NewStockAdjustment(productID entity.ID, delta int64, reason StockReason, note, userID string) (*StockAdjustment, error) {
+	adjustment := &StockAdjustment{
+		ID:        entity.NewID(),
+		ProductID: productID,
+		Delta:     delta,
+		Reason:    reason,
+		Note:      note,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+	if err := adjustment.Validate(); err != nil {
+		return nil, err
+	}
+	return adjustment, nil
+}
+
+func (a *StockAdjustment) Validate() error {
+	if a.Delta == 0 {
+		return ErrInvalidStockDelta
+	}
+	if !a.Reason.IsValid() {
+		return ErrInvalidStockReason
+	}
+	return nil
+}
+
+// ReservationStatus is the state of a reservation. Only pending ones hold stock.
+type ReservationStatus string
+
+const (
+	ReservationPending   ReservationStatus = "pending"
+	ReservationCommitted ReservationStatus = "committed"
+	ReservationReleased  ReservationStatus = "released"
+	ReservationExpired   ReservationStatus = "expired"
+)
+
+// StockReservation holds units of products until it is committed, which
+// takes them out of stock, or released or expired, which gives them back.
+type StockReservation struct {
+	ID        entity.ID              `json:"id"`
+	Status    ReservationStatus      `json:"status"`
+	Items     []StockReservationItem `json:"items" gorm:"foreignKey:ReservationID"`
+	ExpiresAt time.Time              `json:"expires_at"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+type StockReservationItem struct {
+	ReservationID entity.ID `json:"-" gorm:"primaryKey"`
+	ProductID     entity.ID `json:"product_id" gorm:"primaryKey"`
+	Quantity      int64     `json:"quantity"`
+}
+
+// NewStockReservation returns a pending reservation of items expiring after
+// ttl. Items are sorted by product, so concurrent reservations lock the
+// stock of their products in the same order.
+func NewStockReservation(items []StockReservationItem, ttl time.Duration) (*StockReservation, error) {
+	now := time.Now()
+	reservation := &StockReservation{
+		ID:        entity.NewID(),
+		Status:    ReservationPending,
+		Items:     make([]StockReservationItem, len(items)),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	copy(reservation.Items, items)
+	for i := range reservation.Items {
+		reservation.Items[i].ReservationID = reservation.ID
+	}
+	sort.Slice(reservation.Items, func(i, j int) bool {
+		return reservation.Items[i].ProductID.String() < reservation.Items[j].ProductID.String()
+	})
+
+	if ttl < time.Second || ttl > MaxReservationTTL {
+		return nil, ErrInvalidReservationTTL
+	}
+	if err := reservation.Validate(); err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+func (r *StockReservation) Validate() error {
+	if len(r.Items) == 0 {
+		return ErrEmptyReservation
+	}
+	seen := make(map[entity.ID]bool, len(r.Items))
+	for _, item := range r.Items {
+		if item.Quantity <= 0 {
+			return ErrInvalidQuantity
+		}
+		if seen[item.ProductID] {
+			return ErrDuplicatedReservation
+		}
+		seen[item.ProductID] = true
+	}
+	return nil
+}
+
+// IsExpired reports whether a pending reservation stopped holding stock at now.
+func (r *StockReservation) IsExpired(now time.Time) bool {
+	return r.Status == ReservationPending && !now.Before(r.ExpiresAt)
+}
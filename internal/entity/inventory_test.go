@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"encoding/json"
+	"github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestInventory_JSON(t *testing.T) {
+	productID := entity.NewID()
+	data, err := json.Marshal(Inventory{ProductID: productID, OnHand: 10, Reserved: 3})
+	assert.Nil(t, err)
+
+	var decoded map[string]interface{}
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, productID.String(), decoded["product_id"])
+	assert.Equal(t, float64(7), decoded["available"])
+}
+
+func TestNewStockAdjustment(t *testing.T) {
+	adjustment, err := NewStockAdjustment(entity.NewID(), 5, StockReasonRestock, "", "user")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, adjustment.ID)
+
+	_, err = NewStockAdjustment(entity.NewID(), 0, StockReasonRestock, "", "user")
+	assert.Equal(t, ErrInvalidStockDelta, err)
+
+	_, err = NewStockAdjustment(entity.NewID(), 5, "gift", "", "user")
+	assert.Equal(t, ErrInvalidStockReason, err)
+}
+
+func TestNewStockReservation(t *testing.T) {
+	first, second := entity.NewID(), entity.NewID()
+	reservation, err := NewStockReservation([]StockReservationItem{
+		{ProductID: first, Quantity: 1},
+		{ProductID: second, Quantity: 2},
+	}, time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, ReservationPending, reservation.Status)
+	assert.Len(t, reservation.Items, 2)
+	assert.True(t, reservation.Items[0].ProductID.String() < reservation.Items[1].ProductID.String())
+	assert.Equal(t, reservation.ID, reservation.Items[0].ReservationID)
+	assert.False(t, reservation.IsExpired(time.Now()))
+	assert.True(t, reservation.IsExpired(time.Now().Add(time.Minute)))
+
+	_, err = NewStockReservation(nil, time.Minute)
+	assert.Equal(t, ErrEmptyReservation, err)
+
+	_, err = NewStockReservation([]StockReservationItem{{ProductID: first, Quantity: 0}}, time.Minute)
+	assert.Equal(t, ErrInvalidQuantity, err)
+
+	_, err = NewStockReservation([]StockReservationItem{{ProductID: first, Quantity: 1}, {ProductID: first, Quantity: 1}}, time.Minute)
+	assert.Equal(t, ErrDuplicatedReservation, err)
+
+	_, err = NewStockReservation([]StockReservationItem{{ProductID: first, Quantity: 1}}, 0)
+	assert.Equal(t, ErrInvalidReservationTTL, err)
+
+	_, err = NewStockReservation([]StockReservationItem{{ProductID: first, Quantity: 1}}, MaxReservationTTL+time.Second)
+	assert.Equal(t, ErrInvalidReservationTTL, err)
+}
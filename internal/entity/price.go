@@ -0,0 +1,123 @@
+package entity
+
+import (
+	"errors"
+	"github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"time"
+)
+
+var (
+	ErrStartsAtIsRequired = errors.New("starts_at is required")
+	ErrScheduleInThePast  = errors.New("a price can't be scheduled in the past")
+	ErrInvalidScheduleEnd = errors.New("ends_at must be after starts_at")
+)
+
+// PriceChange records a price a product was given, by whom and when.
+type PriceChange struct {
+	ID        entity.ID    `json:"id"`
+	ProductID entity.ID    `json:"product_id"`
+	Price     entity.Money `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+	// ChangedBy is the user who changed the price, or who scheduled it
+	ChangedBy string `json:"changed_by"`
+	// ScheduledPriceID is the scheduled price that made the change, if any
+	ScheduledPriceID *entity.ID `json:"scheduled_price_id"`
+	CreatedAt        time.Time  `json:"changed_at"`
+}
+
+func (PriceChange) TableName() string { return "price_history" }
+
+func NewPriceChange(productID entity.ID, price entity.Money, changedBy string, scheduledPriceID *entity.ID) *PriceChange {
+	return &PriceChange{
+		ID:               entity.NewID(),
+		ProductID:        productID,
+		Price:            price,
+		ChangedBy:        changedBy,
+		ScheduledPriceID: scheduledPriceID,
+		CreatedAt:        time.Now(),
+	}
+}
+
+// ScheduledPriceStatus tells where a scheduled price is in its life: pending
+// until it starts, active while a promotion with an end runs, and finished
+// once the product got its price and, with an end, got the previous one back.
+type ScheduledPriceStatus string
+
+const (
+	ScheduledPricePending  ScheduledPriceStatus = "pending"
+	ScheduledPriceActive   ScheduledPriceStatus = "active"
+	ScheduledPriceFinished ScheduledPriceStatus = "finished"
+	ScheduledPriceCanceled ScheduledPriceStatus = "canceled"
+)
+
+// ScheduledPrice is a price a product gets from StartsAt on. With an EndsAt it
+// is a promotion, after which the product gets back the price it had before.
+type ScheduledPrice struct {
+	ID        entity.ID    `json:"id"`
+	ProductID entity.ID    `json:"product_id"`
+	Price     entity.Money `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+	StartsAt  time.Time    `json:"starts_at"`
+	// EndsAt is empty for a price that stays
+	EndsAt *time.Time           `json:"ends_at"`
+	Status ScheduledPriceStatus `json:"status"`
+	// RestorePrice is the price the product had when the scheduled one started
+	RestorePrice *entity.Money `json:"-" gorm:"embedded;embeddedPrefix:restore_price_"`
+	CreatedBy    string        `json:"created_by"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+func NewScheduledPrice(productID entity.ID, price entity.Money, startsAt time.Time, endsAt *time.Time, createdBy string) (*ScheduledPrice, error) {
+	now := time.Now()
+	scheduled := &ScheduledPrice{
+		ID:        entity.NewID(),
+		ProductID: productID,
+		Price:     price,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		Status:    ScheduledPricePending,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := scheduled.Validate(); err != nil {
+		return nil, err
+	}
+	if scheduled.StartsAt.Before(now.Add(-time.Minute)) {
+		return nil, ErrScheduleInThePast
+	}
+	return scheduled, nil
+}
+
+func (s *ScheduledPrice) Validate() error {
+	if s.Price.Amount == 0 {
+		return ErrPriceIsRequired
+	}
+	if s.Price.Amount < 0 {
+		return ErrInvalidPrice
+	}
+	if !entity.IsCurrency(s.Price.Currency) {
+		return entity.ErrInvalidCurrency
+	}
+	if s.StartsAt.IsZero() {
+		return ErrStartsAtIsRequired
+	}
+	if s.EndsAt != nil && !s.EndsAt.After(s.StartsAt) {
+		return ErrInvalidScheduleEnd
+	}
+	return nil
+}
+
+// Overlaps reports whether s and other would be in effect at the same time,
+// where a price without an end is only in effect at its start.
+func (s *ScheduledPrice) Overlaps(other *ScheduledPrice) bool {
+	start, end := s.window()
+	otherStart, otherEnd := other.window()
+	return start.Before(otherEnd) && otherStart.Before(end)
+}
+
+func (s *ScheduledPrice) window() (time.Time, time.Time) {
+	if s.EndsAt == nil {
+		return s.StartsAt, s.StartsAt.Add(time.Nanosecond)
+	}
+	return s.StartsAt, *s.EndsAt
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewScheduledPrice(t *testing.T) {
+	productID := entity.NewID()
+	price := entity.Money{Amount: 900, Currency: "USD"}
+	startsAt := time.Now().Add(time.Hour)
+	endsAt := startsAt.Add(24 * time.Hour)
+
+	scheduled, err := NewScheduledPrice(productID, price, startsAt, &endsAt, "user")
+	assert.Nil(t, err)
+	assert.Equal(t, ScheduledPricePending, scheduled.Status)
+	assert.Nil(t, scheduled.RestorePrice)
+
+	_, err = NewScheduledPrice(productID, entity.Money{Currency: "USD"}, startsAt, nil, "user")
+	assert.Equal(t, ErrPriceIsRequired, err)
+	_, err = NewScheduledPrice(productID, price, time.Time{}, nil, "user")
+	assert.Equal(t, ErrStartsAtIsRequired, err)
+	_, err = NewScheduledPrice(productID, price, time.Now().Add(-time.Hour), nil, "user")
+	assert.Equal(t, ErrScheduleInThePast, err)
+	_, err = NewScheduledPrice(productID, price, startsAt, &startsAt, "user")
+	assert.Equal(t, ErrInvalidScheduleEnd, err)
+}
+
+func TestScheduledPrice_Overlaps(t *testing.T) {
+	at := func(hours int) *time.Time {
+		moment := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(hours) * time.Hour)
+		return &moment
+	}
+	schedule := func(start int, end *time.Time) *ScheduledPrice {
+		return &ScheduledPrice{StartsAt: *at(start), EndsAt: end}
+	}
+
+	promotion := schedule(10, at(20))
+	assert.True(t, promotion.Overlaps(schedule(15, at(25))))
+	assert.True(t, promotion.Overlaps(schedule(5, at(11))))
+	assert.False(t, promotion.Overlaps(schedule(20, at(30))))
+	assert.False(t, promotion.Overlaps(schedule(0, at(10))))
+
+	assert.True(t, promotion.Overlaps(schedule(10, nil)))
+	assert.True(t, schedule(19, nil).Overlaps(promotion))
+	assert.False(t, promotion.Overlaps(schedule(20, nil)))
+	assert.True(t, schedule(30, nil).Overlaps(schedule(30, nil)))
+	assert.False(t, schedule(30, nil).Overlaps(schedule(31, nil)))
+}
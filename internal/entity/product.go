@@ -3,6 +3,7 @@ package entity
 import (
 	"errors"
 	"github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"gorm.io/gorm"
 	"time"
 )
 
@@ -15,19 +16,27 @@ var (
 )
 
 type Product struct {
-	ID          entity.ID `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          entity.ID      `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Price       entity.Money   `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+	OwnerID     entity.ID      `json:"owner_id"`
+	Tags        []string       `json:"tags" gorm:"-"`
+	Images      []ProductImage `json:"images" gorm:"-"`
+	Version     int            `json:"version"`
+	CreatedAt   time.Time      `json:"created_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" swaggertype:"string" format:"date-time"`
 }
 
-func NewProduct(name, description string, price float64) (*Product, error) {
+func NewProduct(name, description string, price entity.Money) (*Product, error) {
 	product := Product{
 		ID:          entity.NewID(),
 		Name:        name,
 		Description: description,
 		Price:       price,
+		Tags:        []string{},
+		Images:      []ProductImage{},
+		Version:     1,
 		CreatedAt:   time.Now(),
 	}
 	if err := product.Validate(); err != nil {
@@ -47,11 +56,40 @@ func (p *Product) Validate() error {
 	if p.Name == "" {
 		return ErrNameIsRequired
 	}
-	if p.Price == 0 {
+	if p.Price.Amount == 0 {
 		return ErrPriceIsRequired
 	}
-	if p.Price < 0.0 {
+	if p.Price.Amount < 0 {
 		return ErrInvalidPrice
 	}
+	if !entity.IsCurrency(p.Price.Currency) {
+		return entity.ErrInvalidCurrency
+	}
+	if len(p.Tags) > MaxProductTags {
+		return ErrTooManyTags
+	}
+	for _, tag := range p.Tags {
+		if err := validateTag(tag); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// SetTags replaces the tags of the product with the normalized tags.
+func (p *Product) SetTags(tags []string) error {
+	normalized, err := NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	p.Tags = normalized
+	return nil
+}
+
+func (p *Product) IsOwnedBy(userID string) bool {
+	return userID != "" && p.OwnerID.String() == userID
+}
+
+func (p *Product) IsDeleted() bool {
+	return p.DeletedAt.Valid
+}
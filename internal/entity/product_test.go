@@ -3,13 +3,19 @@ package entity
 import (
 	"github.com/SchunckLeonardo/go-expert-api/pkg/entity"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 	"testing"
+	"time"
 )
 
+func usd(amount int64) entity.Money {
+	return entity.Money{Amount: amount, Currency: entity.DefaultCurrency}
+}
+
 func TestNewProduct(t *testing.T) {
 	name := "Product 1"
 	description := "Product 1 description"
-	price := 500.50
+	price := usd(50050)
 
 	product, err := NewProduct(name, description, price)
 	assert.Nil(t, err)
@@ -18,32 +24,61 @@ func TestNewProduct(t *testing.T) {
 	assert.Equal(t, name, product.Name)
 	assert.Equal(t, description, product.Description)
 	assert.Equal(t, price, product.Price)
+	assert.Equal(t, 1, product.Version)
 	assert.NotEmpty(t, price, product.Price)
 	assert.IsType(t, entity.ID{}, product.ID)
 }
 
 func TestProductWhenNameIsRequired(t *testing.T) {
-	product, err := NewProduct("", "", 10.0)
+	product, err := NewProduct("", "", usd(1000))
 	assert.Nil(t, product)
 	assert.Equal(t, ErrNameIsRequired, err)
 }
 
 func TestProductWhenPriceIsRequired(t *testing.T) {
-	product, err := NewProduct("Product 1", "", 0)
+	product, err := NewProduct("Product 1", "", usd(0))
 	assert.Nil(t, product)
 	assert.Equal(t, ErrPriceIsRequired, err)
 }
 
 func TestProductWhenPriceIsInvalid(t *testing.T) {
-	product, err := NewProduct("Product 1", "", -50)
+	product, err := NewProduct("Product 1", "", usd(-5000))
 	assert.Nil(t, product)
 	assert.Equal(t, ErrInvalidPrice, err)
 }
 
+func TestProductWhenCurrencyIsInvalid(t *testing.T) {
+	product, err := NewProduct("Product 1", "", entity.Money{Amount: 1000, Currency: "XYZ"})
+	assert.Nil(t, product)
+	assert.Equal(t, entity.ErrInvalidCurrency, err)
+}
+
 func TestProduct_Validate(t *testing.T) {
-	product, err := NewProduct("Product 1", "", 10)
+	product, err := NewProduct("Product 1", "", usd(1000))
 	assert.Nil(t, err)
 	assert.NotNil(t, product)
 
 	assert.Nil(t, product.Validate())
 }
+
+func TestProduct_IsOwnedBy(t *testing.T) {
+	product, err := NewProduct("Product 1", "", usd(1000))
+	assert.Nil(t, err)
+
+	ownerID := entity.NewID()
+	assert.False(t, product.IsOwnedBy(ownerID.String()))
+
+	product.OwnerID = ownerID
+	assert.True(t, product.IsOwnedBy(ownerID.String()))
+	assert.False(t, product.IsOwnedBy(entity.NewID().String()))
+	assert.False(t, product.IsOwnedBy(""))
+}
+
+func TestProduct_IsDeleted(t *testing.T) {
+	product, err := NewProduct("Product 1", "", usd(1000))
+	assert.Nil(t, err)
+	assert.False(t, product.IsDeleted())
+
+	product.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	assert.True(t, product.IsDeleted())
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewRefreshToken(t *testing.T) {
+	userID := entity.NewID()
+	familyID := entity.NewID()
+
+	refreshToken, token, err := NewRefreshToken(userID, familyID, time.Hour)
+	assert.Nil(t, err)
+	assert.NotNil(t, refreshToken)
+	assert.NotEmpty(t, token)
+
+	assert.Equal(t, userID, refreshToken.UserID)
+	assert.Equal(t, familyID, refreshToken.FamilyID)
+	assert.Equal(t, HashRefreshToken(token), refreshToken.TokenHash)
+	assert.NotEqual(t, token, refreshToken.TokenHash)
+	assert.False(t, refreshToken.IsExpired())
+	assert.False(t, refreshToken.IsRevoked())
+
+	_, other, err := NewRefreshToken(userID, familyID, time.Hour)
+	assert.Nil(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestRefreshToken_IsExpired(t *testing.T) {
+	refreshToken, _, err := NewRefreshToken(entity.NewID(), entity.NewID(), -time.Second)
+	assert.Nil(t, err)
+	assert.True(t, refreshToken.IsExpired())
+}
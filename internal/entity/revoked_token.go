@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrTokenRevoked = errors.New("token revoked")
+
+// RevokedToken denies an access token, identified by its jti claim, until it expires.
+type RevokedToken struct {
+	JTI       string    `json:"jti" gorm:"primaryKey"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewRevokedToken(jti string, expiresAt time.Time) *RevokedToken {
+	return &RevokedToken{
+		JTI:       jti,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+}
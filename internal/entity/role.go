@@ -0,0 +1,66 @@
+package entity
+
+import "errors"
+
+var ErrInvalidRole = errors.New("invalid role")
+
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+)
+
+type Permission string
+
+const (
+	PermissionReadProducts   Permission = "products:read"
+	PermissionWriteProducts  Permission = "products:write"
+	PermissionDeleteProducts Permission = "products:delete"
+	PermissionManageUsers    Permission = "users:manage"
+	// PermissionManageExchangeRates allows changing the rates prices are converted with
+	PermissionManageExchangeRates Permission = "exchange_rates:manage"
+	// PermissionManageCategories allows changing the category tree
+	PermissionManageCategories Permission = "categories:manage"
+	// PermissionManageInventory allows adjusting stock and reserving it
+	PermissionManageInventory Permission = "inventory:manage"
+	// PermissionReadAudit allows reading who changed what in the audit log
+	PermissionReadAudit Permission = "audit:read"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermissionReadProducts,
+		PermissionWriteProducts,
+		PermissionDeleteProducts,
+		PermissionManageUsers,
+		PermissionManageExchangeRates,
+		PermissionManageCategories,
+		PermissionManageInventory,
+		PermissionReadAudit,
+	},
+	RoleEditor: {
+		PermissionReadProducts,
+		PermissionWriteProducts,
+		PermissionDeleteProducts,
+		PermissionManageInventory,
+	},
+	RoleViewer: {
+		PermissionReadProducts,
+	},
+}
+
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+func (r Role) Can(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRole_IsValid(t *testing.T) {
+	assert.True(t, RoleAdmin.IsValid())
+	assert.True(t, RoleEditor.IsValid())
+	assert.True(t, RoleViewer.IsValid())
+	assert.False(t, Role("root").IsValid())
+	assert.False(t, Role("").IsValid())
+}
+
+func TestRole_Can(t *testing.T) {
+	assert.True(t, RoleAdmin.Can(PermissionManageUsers))
+	assert.True(t, RoleAdmin.Can(PermissionDeleteProducts))
+	assert.True(t, RoleAdmin.Can(PermissionReadAudit))
+
+	assert.True(t, RoleEditor.Can(PermissionWriteProducts))
+	assert.True(t, RoleEditor.Can(PermissionDeleteProducts))
+	assert.False(t, RoleEditor.Can(PermissionManageUsers))
+	assert.False(t, RoleEditor.Can(PermissionReadAudit))
+
+	assert.True(t, RoleViewer.Can(PermissionReadProducts))
+	assert.False(t, RoleViewer.Can(PermissionWriteProducts))
+	assert.False(t, RoleViewer.Can(PermissionDeleteProducts))
+
+	assert.False(t, Role("").Can(PermissionReadProducts))
+}
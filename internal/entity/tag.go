@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MaxTagLength   = 50
+	MaxProductTags = 20
+)
+
+var (
+	ErrInvalidTag  = errors.New("invalid tag")
+	ErrTooManyTags = fmt.Errorf("a product can't have more than %d tags", MaxProductTags)
+)
+
+// TagUsage is a tag with the number of products labeled with it.
+type TagUsage struct {
+	Tag      string `json:"tag"`
+	Products int    `json:"products"`
+}
+
+// NormalizeTags trims and lowercases tags, drops blank and repeated ones and
+// sorts them, so "New" and " new" are the same tag.
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if err := validateTag(tag); err != nil {
+			return nil, err
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > MaxProductTags {
+		return nil, ErrTooManyTags
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// validateTag rejects tags that are too long or have a comma, which
+// separates tags in filters and CSV files.
+func validateTag(tag string) error {
+	if tag == "" || utf8.RuneCountInString(tag) > MaxTagLength || strings.Contains(tag, ",") {
+		return fmt.Errorf("%w: %q", ErrInvalidTag, tag)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{" New", "clearance", "new", "", "Clearance "})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"clearance", "new"}, tags)
+
+	tags, err = NormalizeTags(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{}, tags)
+
+	_, err = NormalizeTags([]string{"a,b"})
+	assert.ErrorIs(t, err, ErrInvalidTag)
+
+	_, err = NormalizeTags([]string{strings.Repeat("a", MaxTagLength+1)})
+	assert.ErrorIs(t, err, ErrInvalidTag)
+
+	many := make([]string, MaxProductTags+1)
+	for i := range many {
+		many[i] = strings.Repeat("a", i+1)
+	}
+	_, err = NormalizeTags(many)
+	assert.ErrorIs(t, err, ErrTooManyTags)
+}
+
+func TestProduct_SetTags(t *testing.T) {
+	product, err := NewProduct("Laptop", "", usd(1000))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{}, product.Tags)
+
+	assert.Nil(t, product.SetTags([]string{"Sale"}))
+	assert.Equal(t, []string{"sale"}, product.Tags)
+	assert.Nil(t, product.Validate())
+
+	assert.ErrorIs(t, product.SetTags([]string{"a,b"}), ErrInvalidTag)
+	assert.Equal(t, []string{"sale"}, product.Tags)
+
+	product.Tags = []string{""}
+	assert.ErrorIs(t, product.Validate(), ErrInvalidTag)
+}
@@ -1,15 +1,19 @@
 package entity
 
 import (
+	"errors"
 	"github.com/SchunckLeonardo/go-expert-api/pkg/entity"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
 type User struct {
 	ID       entity.ID `json:"id"`
 	Name     string    `json:"name"`
 	Email    string    `json:"email"`
 	Password string    `json:"-"`
+	Role     Role      `json:"role"`
 }
 
 func NewUser(name, email, password string) (*User, error) {
@@ -23,6 +27,7 @@ func NewUser(name, email, password string) (*User, error) {
 		Name:     name,
 		Email:    email,
 		Password: string(hash),
+		Role:     RoleViewer,
 	}, nil
 }
 
@@ -30,3 +35,11 @@ func (u *User) ValidatePassword(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 	return err == nil
 }
+
+func (u *User) SetRole(role Role) error {
+	if !role.IsValid() {
+		return ErrInvalidRole
+	}
+	u.Role = role
+	return nil
+}
@@ -19,6 +19,7 @@ func TestNewUser(t *testing.T) {
 
 	assert.Equal(t, name, user.Name)
 	assert.Equal(t, email, user.Email)
+	assert.Equal(t, RoleViewer, user.Role)
 
 	assert.IsType(t, entity.ID{}, user.ID)
 }
@@ -34,3 +35,14 @@ func TestUser_ValidatePassword(t *testing.T) {
 
 	assert.NotEqual(t, password, user.Password)
 }
+
+func TestUser_SetRole(t *testing.T) {
+	user, err := NewUser("John Doe", "j@j.com", "123456")
+	assert.Nil(t, err)
+
+	assert.Nil(t, user.SetRole(RoleEditor))
+	assert.Equal(t, RoleEditor, user.Role)
+
+	assert.Equal(t, ErrInvalidRole, user.SetRole("root"))
+	assert.Equal(t, RoleEditor, user.Role)
+}
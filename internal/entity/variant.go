@@ -0,0 +1,116 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxSKULength           = 64
+	MaxVariantOptions      = 10
+	MaxVariantOptionLength = 50
+)
+
+var (
+	ErrSKUIsRequired  = errors.New("sku is required")
+	ErrInvalidSKU     = fmt.Errorf("sku must have up to %d letters, digits, dots, dashes or underscores", MaxSKULength)
+	ErrTooManyOptions = fmt.Errorf("a variant can't have more than %d options", MaxVariantOptions)
+	ErrInvalidOption  = errors.New("invalid variant option")
+)
+
+// Variant is a purchasable version of a product, like a shirt in one size
+// and color, identified by its SKU.
+type Variant struct {
+	ID        entity.ID `json:"id"`
+	ProductID entity.ID `json:"product_id"`
+	SKU       string    `json:"sku" example:"SHIRT-M-BLUE"`
+	// Options tell the variant apart from the other ones of the product, like {"size": "M", "color": "blue"}
+	Options map[string]string `json:"options" gorm:"serializer:json"`
+	// Price overrides the price of the product when set
+	Price     *entity.Money `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+func NewVariant(productID entity.ID, sku string, options map[string]string, price *entity.Money) (*Variant, error) {
+	now := time.Now()
+	variant := &Variant{
+		ID:        entity.NewID(),
+		ProductID: productID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := variant.Set(sku, options, price); err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+// Set replaces the SKU, options and price of the variant with their
+// normalized values: the SKU is uppercased, and option names lowercased.
+func (v *Variant) Set(sku string, options map[string]string, price *entity.Money) error {
+	normalized := make(map[string]string, len(options))
+	for name, value := range options {
+		normalized[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+	v.SKU = strings.ToUpper(strings.TrimSpace(sku))
+	v.Options = normalized
+	v.Price = price
+	return v.Validate()
+}
+
+func (v *Variant) Validate() error {
+	if v.ProductID.String() == "" {
+		return ErrIDIsRequired
+	}
+	if v.SKU == "" {
+		return ErrSKUIsRequired
+	}
+	if !isSKU(v.SKU) {
+		return ErrInvalidSKU
+	}
+	if len(v.Options) > MaxVariantOptions {
+		return ErrTooManyOptions
+	}
+	for name, value := range v.Options {
+		if name == "" || value == "" || utf8.RuneCountInString(name) > MaxVariantOptionLength || utf8.RuneCountInString(value) > MaxVariantOptionLength {
+			return fmt.Errorf("%w: %q", ErrInvalidOption, name)
+		}
+	}
+	if v.Price != nil {
+		if v.Price.Amount <= 0 {
+			return ErrInvalidPrice
+		}
+		if !entity.IsCurrency(v.Price.Currency) {
+			return entity.ErrInvalidCurrency
+		}
+	}
+	return nil
+}
+
+// EffectivePrice returns the price the variant is sold for: its own, or the
+// price of product when it doesn't override it.
+func (v *Variant) EffectivePrice(product *Product) entity.Money {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return product.Price
+}
+
+func isSKU(sku string) bool {
+	if len(sku) > MaxSKULength {
+		return false
+	}
+	for _, r := range sku {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
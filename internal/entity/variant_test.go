@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestNewVariant(t *testing.T) {
+	productID := entity.NewID()
+	variant, err := NewVariant(productID, " shirt-m_blue.1 ", map[string]string{" Size ": " M ", "Color": "blue"}, nil)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, variant.ID)
+	assert.Equal(t, "SHIRT-M_BLUE.1", variant.SKU)
+	assert.Equal(t, map[string]string{"size": "M", "color": "blue"}, variant.Options)
+
+	_, err = NewVariant(productID, " ", nil, nil)
+	assert.Equal(t, ErrSKUIsRequired, err)
+	_, err = NewVariant(productID, "SHIRT M", nil, nil)
+	assert.Equal(t, ErrInvalidSKU, err)
+	_, err = NewVariant(productID, strings.Repeat("A", MaxSKULength+1), nil, nil)
+	assert.Equal(t, ErrInvalidSKU, err)
+	_, err = NewVariant(productID, "SHIRT", map[string]string{"size": ""}, nil)
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	options := map[string]string{}
+	for i := 0; i <= MaxVariantOptions; i++ {
+		options[strings.Repeat("o", i+1)] = "x"
+	}
+	_, err = NewVariant(productID, "SHIRT", options, nil)
+	assert.Equal(t, ErrTooManyOptions, err)
+}
+
+func TestVariant_Price(t *testing.T) {
+	product, err := NewProduct("Shirt", "", entity.Money{Amount: 2000, Currency: "USD"})
+	assert.Nil(t, err)
+
+	variant, err := NewVariant(product.ID, "SHIRT-M", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, product.Price, variant.EffectivePrice(product))
+
+	price := entity.Money{Amount: 2500, Currency: "USD"}
+	assert.Nil(t, variant.Set("SHIRT-M", nil, &price))
+	assert.Equal(t, price, variant.EffectivePrice(product))
+
+	assert.Equal(t, ErrInvalidPrice, variant.Set("SHIRT-M", nil, &entity.Money{Amount: 0, Currency: "USD"}))
+	assert.Equal(t, entity.ErrInvalidCurrency, variant.Set("SHIRT-M", nil, &entity.Money{Amount: 10, Currency: "XYZ"}))
+}
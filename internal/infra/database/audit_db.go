@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/logging"
+	"gorm.io/gorm"
+)
+
+type Audit struct {
+	DB *gorm.DB
+}
+
+func NewAudit(db *gorm.DB) *Audit {
+	return &Audit{DB: db}
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx making changes on behalf of userID, who they
+// are audited for. Changes made without an actor, like the ones of the server
+// itself, are audited with an empty one.
+func WithActor(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+func actorFrom(ctx context.Context) string {
+	userID, _ := ctx.Value(actorKey{}).(string)
+	return userID
+}
+
+// Create stores entries in a single statement per batch.
+func (a *Audit) Create(ctx context.Context, entries ...*entity.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return a.DB.WithContext(ctx).CreateInBatches(entries, createBatchSize).Error
+}
+
+// Search returns the entries matching filter, the latest first.
+func (a *Audit) Search(ctx context.Context, filter AuditFilter, page, limit int) ([]entity.AuditEntry, error) {
+	query := a.DB.WithContext(ctx).Model(&entity.AuditEntry{})
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		query = query.Where("resource_id = ?", filter.ResourceID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+	if page != 0 && limit != 0 {
+		query = query.Limit(limit).Offset((page - 1) * limit)
+	}
+
+	var entries []entity.AuditEntry
+	err := query.Order("created_at DESC").Order("id").Find(&entries).Error
+	return entries, err
+}
+
+// recordAudit stores the entry of a change made within tx by the actor and in
+// the request of ctx, so that the change is rolled back when it can't be
+// audited. before and after are the resource as its clients see it, nil when
+// it doesn't exist.
+func recordAudit(ctx context.Context, tx *gorm.DB, action entity.AuditAction, resourceType entity.AuditResource, resourceID string, before, after interface{}) error {
+	var snapshots [2]json.RawMessage
+	for i, resource := range []interface{}{before, after} {
+		if resource == nil {
+			continue
+		}
+		data, err := json.Marshal(resource)
+		if err != nil {
+			return err
+		}
+		snapshots[i] = data
+	}
+	entry := entity.NewAuditEntry(actorFrom(ctx), action, resourceType, resourceID, snapshots[0], snapshots[1], logging.RequestID(ctx))
+	return NewAudit(tx).Create(ctx, entry)
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/logging"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAudit_CreateAndSearch(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+	auditDB := NewAudit(db)
+
+	created := entity.NewAuditEntry("admin", entity.AuditCreate, entity.AuditProduct, "1", nil, json.RawMessage(`{"name":"Laptop"}`), "request-1")
+	created.CreatedAt = time.Now().Add(-time.Hour)
+	deleted := entity.NewAuditEntry("editor", entity.AuditDelete, entity.AuditProduct, "1", json.RawMessage(`{"name":"Laptop"}`), nil, "request-2")
+	role := entity.NewAuditEntry("admin", entity.AuditUpdate, entity.AuditUser, "2", json.RawMessage(`{"role":"viewer"}`), json.RawMessage(`{"role":"editor"}`), "request-3")
+	assert.Nil(t, auditDB.Create(ctx, created, deleted, role))
+	assert.Nil(t, auditDB.Create(ctx))
+
+	entries, err := auditDB.Search(ctx, AuditFilter{}, 0, 0)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, created.ID, entries[2].ID)
+
+	entries, err = auditDB.Search(ctx, AuditFilter{ResourceType: entity.AuditProduct, ResourceID: "1", Action: entity.AuditDelete}, 1, 10)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "editor", entries[0].ActorID)
+	assert.JSONEq(t, `{"name":"Laptop"}`, string(entries[0].Before))
+	assert.Nil(t, entries[0].After)
+
+	entries, err = auditDB.Search(ctx, AuditFilter{ActorID: "admin"}, 1, 1)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, role.ID, entries[0].ID)
+
+	entries, err = auditDB.Search(ctx, AuditFilter{RequestID: "request-3"}, 1, 10)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.JSONEq(t, `{"role":"editor"}`, string(entries[0].After))
+
+	to := time.Now().Add(-time.Minute)
+	entries, err = auditDB.Search(ctx, AuditFilter{To: &to}, 1, 10)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, created.ID, entries[0].ID)
+	entries, err = auditDB.Search(ctx, AuditFilter{From: &to}, 1, 10)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestAudit_RecordedWithTheChange(t *testing.T) {
+	ctx := logging.WithRequestID(WithActor(context.Background(), "editor"), "request-1")
+	db := utils.OpenDBConnection(t)
+	productDB := NewProduct(db)
+	auditDB := NewAudit(db)
+
+	product, err := entity.NewProduct("Shirt", "", usd(2000))
+	assert.Nil(t, err)
+	assert.Nil(t, productDB.Create(ctx, product))
+	product.Name = "Blue shirt"
+	assert.Nil(t, productDB.Update(ctx, product))
+	assert.Nil(t, productDB.DeleteVersion(ctx, product.ID.String(), product.Version))
+	assert.Nil(t, productDB.Restore(ctx, product.ID.String()))
+
+	entries, err := auditDB.Search(ctx, AuditFilter{ResourceID: product.ID.String()}, 0, 0)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 4)
+	for _, entry := range entries {
+		assert.Equal(t, "editor", entry.ActorID)
+		assert.Equal(t, "request-1", entry.RequestID)
+	}
+	updated, err := auditDB.Search(ctx, AuditFilter{Action: entity.AuditUpdate}, 0, 0)
+	assert.Nil(t, err)
+	assert.Len(t, updated, 1)
+	assert.Contains(t, string(updated[0].Before), `"name":"Shirt"`)
+	assert.Contains(t, string(updated[0].After), `"name":"Blue shirt"`)
+
+	// a change that can't be audited is rolled back
+	assert.Nil(t, db.Migrator().DropTable(&entity.AuditEntry{}))
+	product.Name = "Red shirt"
+	assert.NotNil(t, productDB.Update(ctx, product))
+	found, err := productDB.FindByID(ctx, product.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, "Blue shirt", found.Name)
+}
+
+func TestAudit_CategoriesAndScheduledPrices(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+	productDB := NewProduct(db)
+	categoryDB := NewCategory(db)
+	priceDB := NewPrice(db)
+	auditDB := NewAudit(db)
+
+	product, err := entity.NewProduct("Shirt", "", usd(2000))
+	assert.Nil(t, err)
+	assert.Nil(t, productDB.Create(ctx, product))
+	sale := createCategory(t, categoryDB, "Sale", nil)
+
+	assert.Nil(t, categoryDB.SetProductCategories(WithActor(ctx, "editor"), product.ID.String(), []string{sale.ID.String()}))
+	entries, err := auditDB.Search(ctx, AuditFilter{ActorID: "editor", Action: entity.AuditUpdate}, 0, 0)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, entity.AuditProductCategories, entries[0].ResourceType)
+	assert.Equal(t, product.ID.String(), entries[0].ResourceID)
+	assert.JSONEq(t, `{"category_ids":[]}`, string(entries[0].Before))
+	assert.JSONEq(t, `{"category_ids":["`+sale.ID.String()+`"]}`, string(entries[0].After))
+
+	start := time.Now().Add(time.Hour)
+	schedulePrice(t, priceDB, product, usd(1500), start, nil)
+	_, err = priceDB.ApplyScheduled(logging.WithRequestID(ctx, "job-1"), start)
+	assert.Nil(t, err)
+	entries, err = auditDB.Search(ctx, AuditFilter{RequestID: "job-1"}, 0, 0)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Empty(t, entries[0].ActorID)
+	assert.Contains(t, string(entries[0].Before), `"amount":"20.00"`)
+	assert.Contains(t, string(entries[0].After), `"amount":"15.00"`)
+}
@@ -0,0 +1,213 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"slices"
+)
+
+var (
+	ErrParentCategoryNotFound = errors.New("parent category not found")
+	ErrCategoryNotFound       = errors.New("category not found")
+	ErrCategoryHasChildren    = errors.New("category has child categories")
+)
+
+// categoryTreeSQL selects the id of a category and of all its descendants.
+const categoryTreeSQL = `WITH RECURSIVE category_tree(id) AS (
+	SELECT id FROM categories WHERE id = ?
+	UNION
+	SELECT categories.id FROM categories JOIN category_tree ON categories.parent_id = category_tree.id
+) SELECT id FROM category_tree`
+
+// productCategory links a product to one of its categories.
+type productCategory struct {
+	ProductID  string
+	CategoryID string
+}
+
+func (productCategory) TableName() string { return "product_categories" }
+
+// productCategories is the audit snapshot of the categories of a product.
+type productCategories struct {
+	CategoryIDs []string `json:"category_ids"`
+}
+
+type Category struct {
+	DB *gorm.DB
+}
+
+func NewCategory(db *gorm.DB) *Category {
+	return &Category{DB: db}
+}
+
+// Create stores category. It returns ErrParentCategoryNotFound when its parent doesn't exist.
+func (c *Category) Create(ctx context.Context, category *entity.Category) error {
+	return c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkParent(tx, category); err != nil {
+			return err
+		}
+		return tx.Create(category).Error
+	})
+}
+
+// FindAll returns every category ordered by name.
+func (c *Category) FindAll(ctx context.Context) ([]entity.Category, error) {
+	var categories []entity.Category
+	err := c.DB.WithContext(ctx).Order("name").Order("id").Find(&categories).Error
+	return categories, err
+}
+
+func (c *Category) FindByID(ctx context.Context, id string) (*entity.Category, error) {
+	var category entity.Category
+	err := c.DB.WithContext(ctx).Where("id = ?", id).First(&category).Error
+	return &category, err
+}
+
+// FindByProduct returns the categories of a product ordered by name.
+func (c *Category) FindByProduct(ctx context.Context, productID string) ([]entity.Category, error) {
+	var categories []entity.Category
+	err := c.DB.WithContext(ctx).
+		Joins("JOIN product_categories ON product_categories.category_id = categories.id").
+		Where("product_categories.product_id = ?", productID).
+		Order("categories.name").
+		Find(&categories).Error
+	return categories, err
+}
+
+// Descendants returns the id of the category and of all the categories below it.
+func (c *Category) Descendants(ctx context.Context, id string) ([]string, error) {
+	var ids []string
+	err := c.DB.WithContext(ctx).Raw(categoryTreeSQL, id).Scan(&ids).Error
+	return ids, err
+}
+
+// Update saves category. It returns gorm.ErrRecordNotFound when it doesn't
+// exist, ErrParentCategoryNotFound when its new parent doesn't exist and
+// entity.ErrCategoryCycle when the parent is the category itself or one of
+// its descendants.
+func (c *Category) Update(ctx context.Context, category *entity.Category) error {
+	return c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockCategory(tx, category.ID.String()); err != nil {
+			return err
+		}
+
+		// the ancestors of the new parent are locked while they are walked, so
+		// a concurrent move can't close a cycle behind the check
+		seen := map[string]bool{}
+		for parent := category.ParentID; parent != nil; {
+			id := parent.String()
+			if id == category.ID.String() || seen[id] {
+				return entity.ErrCategoryCycle
+			}
+			seen[id] = true
+
+			ancestor, err := lockCategory(tx, id)
+			if errors.Is(err, gorm.ErrRecordNotFound) && parent == category.ParentID {
+				return ErrParentCategoryNotFound
+			}
+			if err != nil {
+				return err
+			}
+			parent = ancestor.ParentID
+		}
+
+		return tx.Model(category).Select("Name", "ParentID").Updates(category).Error
+	})
+}
+
+// Delete removes a category without children and unlinks it from its
+// products. It returns ErrCategoryHasChildren when other categories are below
+// it and gorm.ErrRecordNotFound when there is none.
+func (c *Category) Delete(ctx context.Context, id string) error {
+	return c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var children int64
+		if err := tx.Model(&entity.Category{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			return err
+		}
+		if children > 0 {
+			return ErrCategoryHasChildren
+		}
+
+		if err := tx.Where("category_id = ?", id).Delete(&productCategory{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&entity.Category{})
+		if result.Error == nil && result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return result.Error
+	})
+}
+
+// SetProductCategories replaces the categories of a product and audits the
+// change as one of entity.AuditProductCategories. It returns ErrCategoryNotFound when one of them
+// doesn't exist.
+func (c *Category) SetProductCategories(ctx context.Context, productID string, categoryIDs []string) error {
+	unique := make([]string, 0, len(categoryIDs))
+	seen := make(map[string]bool, len(categoryIDs))
+	for _, id := range categoryIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		linked := []string{}
+		err := tx.Model(&productCategory{}).Where("product_id = ?", productID).Order("category_id").Pluck("category_id", &linked).Error
+		if err != nil {
+			return err
+		}
+
+		if len(unique) > 0 {
+			var found int64
+			if err := tx.Model(&entity.Category{}).Where("id IN ?", unique).Count(&found).Error; err != nil {
+				return err
+			}
+			if int(found) != len(unique) {
+				return ErrCategoryNotFound
+			}
+		}
+
+		if err := tx.Where("product_id = ?", productID).Delete(&productCategory{}).Error; err != nil {
+			return err
+		}
+		if len(unique) > 0 {
+			links := make([]productCategory, len(unique))
+			for i, id := range unique {
+				links[i] = productCategory{ProductID: productID, CategoryID: id}
+			}
+			if err := tx.Create(&links).Error; err != nil {
+				return err
+			}
+		}
+		relinked := slices.Clone(unique)
+		slices.Sort(relinked)
+		return recordAudit(ctx, tx, entity.AuditUpdate, entity.AuditProductCategories, productID,
+			productCategories{CategoryIDs: linked}, productCategories{CategoryIDs: relinked})
+	})
+}
+
+// lockCategory finds the category id and locks its row until tx ends.
+func lockCategory(tx *gorm.DB, id string) (*entity.Category, error) {
+	var category entity.Category
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&category).Error
+	return &category, err
+}
+
+func checkParent(tx *gorm.DB, category *entity.Category) error {
+	if category.ParentID == nil {
+		return nil
+	}
+	var parents int64
+	if err := tx.Model(&entity.Category{}).Where("id = ?", category.ParentID.String()).Count(&parents).Error; err != nil {
+		return err
+	}
+	if parents == 0 {
+		return ErrParentCategoryNotFound
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package database
+
+import (
+	"context"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func createCategory(t *testing.T, categoryDB *Category, name string, parent *entity.Category) *entity.Category {
+	ctx := context.Background()
+	var parentID *entity2.ID
+	if parent != nil {
+		parentID = &parent.ID
+	}
+	category, err := entity.NewCategory(name, parentID)
+	assert.Nil(t, err)
+	assert.Nil(t, categoryDB.Create(ctx, category))
+	return category
+}
+
+func TestCategory_CRUD(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	categoryDB := NewCategory(db)
+	electronics := createCategory(t, categoryDB, "Electronics", nil)
+	laptops := createCategory(t, categoryDB, "Laptops", electronics)
+
+	found, err := categoryDB.FindByID(ctx, laptops.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, "Laptops", found.Name)
+	assert.Equal(t, electronics.ID, *found.ParentID)
+
+	orphanParent := entity2.NewID()
+	orphan, err := entity.NewCategory("Orphan", &orphanParent)
+	assert.Nil(t, err)
+	assert.ErrorIs(t, categoryDB.Create(ctx, orphan), ErrParentCategoryNotFound)
+
+	assert.Nil(t, categoryDB.Update(ctx, found))
+	found.ParentID = &orphanParent
+	assert.ErrorIs(t, categoryDB.Update(ctx, found), ErrParentCategoryNotFound)
+	assert.ErrorIs(t, categoryDB.Update(ctx, orphan), gorm.ErrRecordNotFound)
+
+	found.Name = "Notebooks"
+	found.ParentID = nil
+	assert.Nil(t, categoryDB.Update(ctx, found))
+
+	categories, err := categoryDB.FindAll(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, categories, 2)
+	assert.Equal(t, "Electronics", categories[0].Name)
+	assert.Equal(t, "Notebooks", categories[1].Name)
+	assert.Nil(t, categories[1].ParentID)
+
+	assert.Nil(t, categoryDB.Delete(ctx, laptops.ID.String()))
+	_, err = categoryDB.FindByID(ctx, laptops.ID.String())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, categoryDB.Delete(ctx, laptops.ID.String()), gorm.ErrRecordNotFound)
+}
+
+func TestCategory_Descendants(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	categoryDB := NewCategory(db)
+	electronics := createCategory(t, categoryDB, "Electronics", nil)
+	computers := createCategory(t, categoryDB, "Computers", electronics)
+	laptops := createCategory(t, categoryDB, "Laptops", computers)
+	createCategory(t, categoryDB, "Books", nil)
+
+	ids, err := categoryDB.Descendants(ctx, electronics.ID.String())
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{electronics.ID.String(), computers.ID.String(), laptops.ID.String()}, ids)
+
+	ids, err = categoryDB.Descendants(ctx, laptops.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{laptops.ID.String()}, ids)
+}
+
+func TestCategory_UpdateCycle(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	categoryDB := NewCategory(db)
+	electronics := createCategory(t, categoryDB, "Electronics", nil)
+	computers := createCategory(t, categoryDB, "Computers", electronics)
+	laptops := createCategory(t, categoryDB, "Laptops", computers)
+
+	electronics.ParentID = &laptops.ID
+	assert.ErrorIs(t, categoryDB.Update(ctx, electronics), entity.ErrCategoryCycle)
+
+	electronics.ParentID = &electronics.ID
+	assert.ErrorIs(t, categoryDB.Update(ctx, electronics), entity.ErrCategoryCycle)
+
+	found, err := categoryDB.FindByID(ctx, electronics.ID.String())
+	assert.Nil(t, err)
+	assert.Nil(t, found.ParentID)
+}
+
+func TestCategory_DeleteWithChildren(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	categoryDB := NewCategory(db)
+	electronics := createCategory(t, categoryDB, "Electronics", nil)
+	createCategory(t, categoryDB, "Laptops", electronics)
+
+	assert.ErrorIs(t, categoryDB.Delete(ctx, electronics.ID.String()), ErrCategoryHasChildren)
+}
+
+func TestCategory_SetProductCategories(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	categoryDB := NewCategory(db)
+	electronics := createCategory(t, categoryDB, "Electronics", nil)
+	sale := createCategory(t, categoryDB, "Sale", nil)
+
+	product, err := entity.NewProduct("Laptop", "Macbook M1", usd(110000))
+	assert.Nil(t, err)
+	db.Create(product)
+
+	assert.Nil(t, categoryDB.SetProductCategories(ctx, product.ID.String(), []string{sale.ID.String(), electronics.ID.String(), sale.ID.String()}))
+	categories, err := categoryDB.FindByProduct(ctx, product.ID.String())
+	assert.Nil(t, err)
+	assert.Len(t, categories, 2)
+	assert.Equal(t, "Electronics", categories[0].Name)
+
+	err = categoryDB.SetProductCategories(ctx, product.ID.String(), []string{sale.ID.String(), entity2.NewID().String()})
+	assert.ErrorIs(t, err, ErrCategoryNotFound)
+	categories, _ = categoryDB.FindByProduct(ctx, product.ID.String())
+	assert.Len(t, categories, 2)
+
+	assert.Nil(t, categoryDB.SetProductCategories(ctx, product.ID.String(), []string{sale.ID.String()}))
+	categories, _ = categoryDB.FindByProduct(ctx, product.ID.String())
+	assert.Len(t, categories, 1)
+
+	assert.Nil(t, categoryDB.Delete(ctx, sale.ID.String()))
+	categories, _ = categoryDB.FindByProduct(ctx, product.ID.String())
+	assert.Empty(t, categories)
+}
+
+func TestProduct_SearchByCategory(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	categoryDB := NewCategory(db)
+	electronics := createCategory(t, categoryDB, "Electronics", nil)
+	laptops := createCategory(t, categoryDB, "Laptops", electronics)
+	books := createCategory(t, categoryDB, "Books", nil)
+
+	productDB := NewProduct(db)
+	for name, category := range map[string]*entity.Category{"Laptop": laptops, "TV": electronics, "Novel": books} {
+		product, err := entity.NewProduct(name, "", usd(1000))
+		assert.Nil(t, err)
+		assert.Nil(t, productDB.Create(ctx, product))
+		assert.Nil(t, categoryDB.SetProductCategories(ctx, product.ID.String(), []string{category.ID.String()}))
+	}
+
+	products, err := productDB.Search(ctx, ProductFilter{CategoryID: electronics.ID.String()}, 0, 0, "name")
+	assert.Nil(t, err)
+	assert.Len(t, products, 2)
+	assert.Equal(t, "Laptop", products[0].Name)
+	assert.Equal(t, "TV", products[1].Name)
+
+	count, err := productDB.Count(ctx, ProductFilter{CategoryID: laptops.ID.String()})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestProduct_PurgeDeletedUnlinksCategories(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	categoryDB := NewCategory(db)
+	electronics := createCategory(t, categoryDB, "Electronics", nil)
+
+	productDB := NewProduct(db)
+	product, err := entity.NewProduct("Laptop", "", usd(1000))
+	assert.Nil(t, err)
+	assert.Nil(t, productDB.Create(ctx, product))
+	assert.Nil(t, categoryDB.SetProductCategories(ctx, product.ID.String(), []string{electronics.ID.String()}))
+	assert.Nil(t, productDB.Delete(ctx, product.ID.String()))
+
+	purged, err := productDB.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	var links int64
+	db.Model(&productCategory{}).Count(&links)
+	assert.Equal(t, int64(0), links)
+}
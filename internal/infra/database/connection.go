@@ -0,0 +1,117 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/configs"
+	"github.com/go-sql-driver/mysql"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"net"
+	"net/url"
+)
+
+const (
+	DriverSqlite   = "sqlite"
+	DriverPostgres = "postgres"
+	DriverMysql    = "mysql"
+
+	SqliteMemory = ":memory:"
+)
+
+var (
+	ErrUnsupportedDriver  = errors.New("unsupported database driver")
+	ErrDatabaseIsRequired = errors.New("database name is required")
+)
+
+// BuildDSN returns the data source name for the driver configured in config.
+// Sqlite uses DBDatabase as the file path, or an in-memory database when it is ":memory:".
+func BuildDSN(config *configs.Conf) (string, error) {
+	if config.DBDatabase == "" {
+		return "", ErrDatabaseIsRequired
+	}
+
+	switch driver(config) {
+	case DriverSqlite:
+		if config.DBDatabase == SqliteMemory {
+			return "file::memory:", nil
+		}
+		return config.DBDatabase, nil
+	case DriverPostgres:
+		sslMode := config.DBSSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(config.DBUser, config.DBPassword),
+			Host:     net.JoinHostPort(config.DBHost, port(config.DBPort, "5432")),
+			Path:     "/" + config.DBDatabase,
+			RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+		}
+		return dsn.String(), nil
+	case DriverMysql:
+		dsn := mysql.NewConfig()
+		dsn.User = config.DBUser
+		dsn.Passwd = config.DBPassword
+		dsn.Net = "tcp"
+		dsn.Addr = net.JoinHostPort(config.DBHost, port(config.DBPort, "3306"))
+		dsn.DBName = config.DBDatabase
+		dsn.ParseTime = true
+		dsn.Params = map[string]string{"charset": "utf8mb4"}
+		return dsn.FormatDSN(), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedDriver, config.DBDriver)
+	}
+}
+
+// NewConnection opens a *gorm.DB using the driver and credentials from config.
+func NewConnection(config *configs.Conf) (*gorm.DB, error) {
+	dsn, err := BuildDSN(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var dialector gorm.Dialector
+	switch driver(config) {
+	case DriverSqlite:
+		dialector = sqlite.Open(dsn)
+	case DriverPostgres:
+		dialector = postgres.Open(dsn)
+	case DriverMysql:
+		dialector = gormMysql.Open(dsn)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: newQueryLogger()})
+	if err != nil {
+		return nil, err
+	}
+
+	if driver(config) == DriverSqlite && config.DBDatabase == SqliteMemory {
+		// every new connection to an in-memory sqlite gets its own empty database,
+		// so the pool must keep a single one open
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		sqlDB.SetMaxOpenConns(1)
+	}
+
+	return db, nil
+}
+
+func driver(config *configs.Conf) string {
+	if config.DBDriver == "" {
+		return DriverSqlite
+	}
+	return config.DBDriver
+}
+
+func port(configured, fallback string) string {
+	if configured == "" {
+		return fallback
+	}
+	return configured
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"gorm.io/gorm"
+)
+
+var ErrExchangeRateExists = errors.New("currency already has an exchange rate")
+
+type ExchangeRate struct {
+	DB *gorm.DB
+}
+
+func NewExchangeRate(db *gorm.DB) *ExchangeRate {
+	return &ExchangeRate{DB: db}
+}
+
+// Create stores rate. It returns ErrExchangeRateExists when its currency
+// already has a rate.
+func (e *ExchangeRate) Create(ctx context.Context, rate *entity.ExchangeRate) error {
+	tx := e.DB.WithContext(ctx)
+	if err := tx.Create(rate).Error; err != nil {
+		if duplicatedKey(tx, err) {
+			return ErrExchangeRateExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (e *ExchangeRate) FindAll(ctx context.Context) ([]entity.ExchangeRate, error) {
+	var rates []entity.ExchangeRate
+	err := e.DB.WithContext(ctx).Order("currency").Find(&rates).Error
+	return rates, err
+}
+
+func (e *ExchangeRate) FindByCurrency(ctx context.Context, currency string) (*entity.ExchangeRate, error) {
+	var rate entity.ExchangeRate
+	err := e.DB.WithContext(ctx).Where("currency = ?", currency).First(&rate).Error
+	return &rate, err
+}
+
+func (e *ExchangeRate) Update(ctx context.Context, rate *entity.ExchangeRate) error {
+	return e.DB.WithContext(ctx).Save(rate).Error
+}
+
+// Delete removes the rate of currency. It returns gorm.ErrRecordNotFound
+// when there is none.
+func (e *ExchangeRate) Delete(ctx context.Context, currency string) error {
+	result := e.DB.WithContext(ctx).Where("currency = ?", currency).Delete(&entity.ExchangeRate{})
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// Converter returns a converter with every stored rate.
+func (e *ExchangeRate) Converter(ctx context.Context) (*entity.CurrencyConverter, error) {
+	rates, err := e.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return entity.NewCurrencyConverter(rates), nil
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestExchangeRate_CRUD(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	exchangeRateDB := NewExchangeRate(db)
+
+	eur, err := entity.NewExchangeRate("EUR", "0.92", entity2.DefaultRounding)
+	assert.Nil(t, err)
+	assert.Nil(t, exchangeRateDB.Create(ctx, eur))
+	assert.ErrorIs(t, exchangeRateDB.Create(ctx, eur), ErrExchangeRateExists)
+
+	chf, err := entity.NewExchangeRate("CHF", "0.9", entity2.Rounding{Mode: entity2.RoundHalfUp, Increment: 5})
+	assert.Nil(t, err)
+	assert.Nil(t, exchangeRateDB.Create(ctx, chf))
+
+	rates, err := exchangeRateDB.FindAll(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, rates, 2)
+	assert.Equal(t, "CHF", rates[0].Currency)
+	assert.Equal(t, int64(5), rates[0].RoundingIncrement)
+
+	eur.Rate = "0.95"
+	assert.Nil(t, exchangeRateDB.Update(ctx, eur))
+	found, err := exchangeRateDB.FindByCurrency(ctx, "EUR")
+	assert.Nil(t, err)
+	assert.Equal(t, "0.95", found.Rate)
+
+	converter, err := exchangeRateDB.Converter(ctx)
+	assert.Nil(t, err)
+	converted, err := converter.Convert(entity2.Money{Amount: 1000, Currency: "USD"}, "EUR")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(950), converted.Amount)
+
+	assert.Nil(t, exchangeRateDB.Delete(ctx, "EUR"))
+	assert.ErrorIs(t, exchangeRateDB.Delete(ctx, "EUR"), gorm.ErrRecordNotFound)
+	_, err = exchangeRateDB.FindByCurrency(ctx, "EUR")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"gorm.io/gorm"
+)
+
+type Image struct {
+	DB *gorm.DB
+}
+
+func NewImage(db *gorm.DB) *Image {
+	return &Image{DB: db}
+}
+
+func (i *Image) Create(ctx context.Context, image *entity.ProductImage) error {
+	return i.DB.WithContext(ctx).Create(image).Error
+}
+
+// FindByID finds an image of the product productID.
+func (i *Image) FindByID(ctx context.Context, productID, id string) (*entity.ProductImage, error) {
+	var image entity.ProductImage
+	err := i.DB.WithContext(ctx).Where("id = ? AND product_id = ?", id, productID).First(&image).Error
+	return &image, err
+}
+
+func (i *Image) Delete(ctx context.Context, id string) error {
+	result := i.DB.WithContext(ctx).Where("id = ?", id).Delete(&entity.ProductImage{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindOrphaned returns up to limit images whose product was purged, which
+// still have blobs in the storage.
+func (i *Image) FindOrphaned(ctx context.Context, limit int) ([]entity.ProductImage, error) {
+	var images []entity.ProductImage
+	err := i.DB.WithContext(ctx).
+		Where("NOT EXISTS (SELECT 1 FROM products WHERE products.id = product_images.product_id)").
+		Order("created_at").
+		Limit(limit).
+		Find(&images).Error
+	return images, err
+}
+
+// loadImages fills the images of products with a single query, the oldest first.
+func loadImages(db *gorm.DB, products ...*entity.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	byID := make(map[string]*entity.Product, len(products))
+	ids := make([]string, len(products))
+	for i, product := range products {
+		product.Images = []entity.ProductImage{}
+		byID[product.ID.String()] = product
+		ids[i] = product.ID.String()
+	}
+
+	var images []entity.ProductImage
+	if err := db.Where("product_id IN ?", ids).Order("created_at").Order("id").Find(&images).Error; err != nil {
+		return err
+	}
+	for _, image := range images {
+		product := byID[image.ProductID.String()]
+		product.Images = append(product.Images, image)
+	}
+	return nil
+}
+
+// loadRelated fills the tags and images of products.
+func loadRelated(db *gorm.DB, products ...*entity.Product) error {
+	if err := loadTags(db, products...); err != nil {
+		return err
+	}
+	return loadImages(db, products...)
+}
+
+func loadRelatedOf(db *gorm.DB, products []entity.Product) error {
+	pointers := make([]*entity.Product, len(products))
+	for i := range products {
+		pointers[i] = &products[i]
+	}
+	return loadRelated(db, pointers...)
+}
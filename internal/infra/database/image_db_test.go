@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func createProductImage(t *testing.T, imageDB *Image, product *entity.Product) *entity.ProductImage {
+	ctx := context.Background()
+	image, err := entity.NewProductImage(product.ID, "image/png", 2048, 640, 480)
+	assert.Nil(t, err)
+	image.Key = image.StorageKey("", ".png")
+	image.ThumbnailKey = image.StorageKey("_thumb", ".png")
+	image.URL = "http://localhost:8080/images/" + image.Key
+	image.ThumbnailURL = "http://localhost:8080/images/" + image.ThumbnailKey
+	assert.Nil(t, imageDB.Create(ctx, image))
+	return image
+}
+
+func TestImage_CreateAndLoad(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+	productDB := NewProduct(db)
+	imageDB := NewImage(db)
+
+	product, err := entity.NewProduct("Laptop", "", usd(1000))
+	assert.Nil(t, err)
+	assert.Nil(t, productDB.Create(ctx, product))
+	first := createProductImage(t, imageDB, product)
+	second := createProductImage(t, imageDB, product)
+
+	found, err := productDB.FindByID(ctx, product.ID.String())
+	assert.Nil(t, err)
+	assert.Len(t, found.Images, 2)
+	assert.Equal(t, first.URL, found.Images[0].URL)
+	assert.Equal(t, second.ThumbnailKey, found.Images[1].ThumbnailKey)
+
+	products, err := productDB.Search(ctx, ProductFilter{}, 1, 10, "")
+	assert.Nil(t, err)
+	assert.Len(t, products[0].Images, 2)
+
+	image, err := imageDB.FindByID(ctx, product.ID.String(), first.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, first.Key, image.Key)
+	_, err = imageDB.FindByID(ctx, entity2.NewID().String(), first.ID.String())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	assert.Nil(t, imageDB.Delete(ctx, first.ID.String()))
+	assert.ErrorIs(t, imageDB.Delete(ctx, first.ID.String()), gorm.ErrRecordNotFound)
+	found, err = productDB.FindByID(ctx, product.ID.String())
+	assert.Nil(t, err)
+	assert.Len(t, found.Images, 1)
+}
+
+func TestImage_FindOrphaned(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+	productDB := NewProduct(db)
+	imageDB := NewImage(db)
+
+	kept, err := entity.NewProduct("Laptop", "", usd(1000))
+	assert.Nil(t, err)
+	assert.Nil(t, productDB.Create(ctx, kept))
+	createProductImage(t, imageDB, kept)
+
+	purged, err := entity.NewProduct("Mouse", "", usd(100))
+	assert.Nil(t, err)
+	assert.Nil(t, productDB.Create(ctx, purged))
+	image := createProductImage(t, imageDB, purged)
+
+	assert.Nil(t, productDB.Delete(ctx, purged.ID.String()))
+	orphaned, err := imageDB.FindOrphaned(ctx, 10)
+	assert.Nil(t, err)
+	assert.Empty(t, orphaned)
+
+	_, err = productDB.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+	assert.Nil(t, err)
+	orphaned, err = imageDB.FindOrphaned(ctx, 10)
+	assert.Nil(t, err)
+	assert.Len(t, orphaned, 1)
+	assert.Equal(t, image.ID, orphaned[0].ID)
+}
@@ -1,17 +1,146 @@
 package database
 
-import "github.com/SchunckLeonardo/go-expert-api/internal/entity"
+import (
+	"context"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"time"
+)
 
 type UserInterface interface {
-	Create(user *entity.User) error
-	FindByEmail(email string) (*entity.User, error)
+	Create(ctx context.Context, user *entity.User) error
+	FindByEmail(ctx context.Context, email string) (*entity.User, error)
+	FindByID(ctx context.Context, id string) (*entity.User, error)
+	Update(ctx context.Context, user *entity.User) error
+}
+
+type RefreshTokenInterface interface {
+	Create(ctx context.Context, token *entity.RefreshToken) error
+	FindByHash(ctx context.Context, hash string) (*entity.RefreshToken, error)
+	Rotate(ctx context.Context, current, next *entity.RefreshToken) error
+	RevokeFamily(ctx context.Context, familyID string) error
+}
+
+type RevokedTokenInterface interface {
+	Create(ctx context.Context, token *entity.RevokedToken) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type ExchangeRateInterface interface {
+	Create(ctx context.Context, rate *entity.ExchangeRate) error
+	FindAll(ctx context.Context) ([]entity.ExchangeRate, error)
+	FindByCurrency(ctx context.Context, currency string) (*entity.ExchangeRate, error)
+	Update(ctx context.Context, rate *entity.ExchangeRate) error
+	Delete(ctx context.Context, currency string) error
+	Converter(ctx context.Context) (*entity.CurrencyConverter, error)
+}
+
+type InventoryInterface interface {
+	FindByProduct(ctx context.Context, productID string) (*entity.Inventory, error)
+	Adjust(ctx context.Context, adjustment *entity.StockAdjustment) (*entity.Inventory, error)
+	FindAdjustments(ctx context.Context, productID string, page, limit int) ([]entity.StockAdjustment, error)
+	Reserve(ctx context.Context, reservation *entity.StockReservation) error
+	FindReservation(ctx context.Context, id string) (*entity.StockReservation, error)
+	CommitReservation(ctx context.Context, id, userID string) (*entity.StockReservation, error)
+	ReleaseReservation(ctx context.Context, id string) (*entity.StockReservation, error)
+	ReleaseExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type ImageInterface interface {
+	Create(ctx context.Context, image *entity.ProductImage) error
+	FindByID(ctx context.Context, productID, id string) (*entity.ProductImage, error)
+	Delete(ctx context.Context, id string) error
+	FindOrphaned(ctx context.Context, limit int) ([]entity.ProductImage, error)
+}
+
+type VariantInterface interface {
+	Create(ctx context.Context, variant *entity.Variant) error
+	FindByProduct(ctx context.Context, productID string) ([]entity.Variant, error)
+	FindByID(ctx context.Context, productID, id string) (*entity.Variant, error)
+	FindBySKU(ctx context.Context, sku string) (*entity.Variant, error)
+	Update(ctx context.Context, variant *entity.Variant) error
+	Delete(ctx context.Context, id string) error
+}
+
+// AuditFilter narrows the entries returned by AuditInterface.Search.
+// Zero values don't filter.
+type AuditFilter struct {
+	ActorID      string
+	Action       entity.AuditAction
+	ResourceType entity.AuditResource
+	ResourceID   string
+	RequestID    string
+	From         *time.Time
+	To           *time.Time
+}
+
+type AuditInterface interface {
+	Create(ctx context.Context, entries ...*entity.AuditEntry) error
+	Search(ctx context.Context, filter AuditFilter, page, limit int) ([]entity.AuditEntry, error)
+}
+
+type PriceInterface interface {
+	FindHistory(ctx context.Context, productID string, page, limit int) ([]entity.PriceChange, error)
+	Schedule(ctx context.Context, scheduled *entity.ScheduledPrice) error
+	FindScheduled(ctx context.Context, productID string) ([]entity.ScheduledPrice, error)
+	FindScheduledByID(ctx context.Context, productID, id string) (*entity.ScheduledPrice, error)
+	CancelScheduled(ctx context.Context, productID, id string) (*entity.ScheduledPrice, error)
+	ApplyScheduled(ctx context.Context, now time.Time) (int64, error)
+}
+
+type TagInterface interface {
+	FindAll(ctx context.Context) ([]entity.TagUsage, error)
+}
+
+type CategoryInterface interface {
+	Create(ctx context.Context, category *entity.Category) error
+	FindAll(ctx context.Context) ([]entity.Category, error)
+	FindByID(ctx context.Context, id string) (*entity.Category, error)
+	FindByProduct(ctx context.Context, productID string) ([]entity.Category, error)
+	Descendants(ctx context.Context, id string) ([]string, error)
+	Update(ctx context.Context, category *entity.Category) error
+	Delete(ctx context.Context, id string) error
+	SetProductCategories(ctx context.Context, productID string, categoryIDs []string) error
+}
+
+// ProductFilter narrows the products returned by ProductInterface.Search.
+// Zero values don't filter.
+type ProductFilter struct {
+	OwnerID string
+	// Text matches name or description, case-insensitively
+	Text string
+	// MinPrices and MaxPrices hold a bound in each currency. A product
+	// matches the bound in its own currency, and never when there is none.
+	MinPrices   []entity2.Money
+	MaxPrices   []entity2.Money
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// Tags matches the products with any of the tags, or with all of them when AllTags is set
+	Tags    []string
+	AllTags bool
+	// CategoryID matches the products in the category or in any category below it
+	CategoryID string
+	// IncludeDeleted also returns soft deleted products
+	IncludeDeleted bool
 }
 
 type ProductInterface interface {
-	Create(product *entity.Product) error
-	FindAll(page, limit int, sort string) ([]entity.Product, error)
-	FindByID(id string) (*entity.Product, error)
-	Update(product *entity.Product) error
-	Delete(id string) error
-	GetProductsCount() (int, error)
+	Create(ctx context.Context, product *entity.Product) error
+	CreateBatch(ctx context.Context, products []*entity.Product) error
+	FindAll(ctx context.Context, page, limit int, sort string) ([]entity.Product, error)
+	Search(ctx context.Context, filter ProductFilter, page, limit int, sort string) ([]entity.Product, error)
+	Count(ctx context.Context, filter ProductFilter) (int, error)
+	Each(ctx context.Context, filter ProductFilter, sort string, fn func(product *entity.Product) error) error
+	SearchByCursor(ctx context.Context, filter ProductFilter, cursor *ProductCursor, limit int, sort string) ([]entity.Product, bool, error)
+	FindByID(ctx context.Context, id string) (*entity.Product, error)
+	FindByIDWithDeleted(ctx context.Context, id string) (*entity.Product, error)
+	Update(ctx context.Context, product *entity.Product) error
+	UpdateBatch(ctx context.Context, products []*entity.Product) error
+	Delete(ctx context.Context, id string) error
+	DeleteVersion(ctx context.Context, id string, version int) error
+	DeleteBatch(ctx context.Context, products []*entity.Product) error
+	Restore(ctx context.Context, id string) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	GetProductsCount(ctx context.Context) (int, error)
 }
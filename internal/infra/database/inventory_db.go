@@ -0,0 +1,217 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var (
+	ErrInsufficientStock     = errors.New("not enough stock available")
+	ErrReservationNotPending = errors.New("reservation is not pending")
+	ErrReservationExpired    = errors.New("reservation expired")
+)
+
+// Inventory keeps the stock of products. Every change is a single
+// conditional UPDATE, so concurrent requests can't take more units than there
+// are without locking rows for longer than the statement.
+type Inventory struct {
+	DB *gorm.DB
+}
+
+func NewInventory(db *gorm.DB) *Inventory {
+	return &Inventory{DB: db}
+}
+
+// FindByProduct returns the stock of a product, which is empty when it was never adjusted.
+func (i *Inventory) FindByProduct(ctx context.Context, productID string) (*entity.Inventory, error) {
+	var inventory entity.Inventory
+	err := i.DB.WithContext(ctx).Where("product_id = ?", productID).First(&inventory).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		inventory.ProductID, err = entity2.ParseID(productID)
+	}
+	return &inventory, err
+}
+
+// Adjust adds adjustment.Delta units on hand and records the adjustment. It
+// returns ErrInsufficientStock when fewer units than are reserved would be left.
+func (i *Inventory) Adjust(ctx context.Context, adjustment *entity.StockAdjustment) (*entity.Inventory, error) {
+	var inventory *entity.Inventory
+	err := i.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		productID := adjustment.ProductID.String()
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&entity.Inventory{ProductID: adjustment.ProductID, UpdatedAt: time.Now()}).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&entity.Inventory{}).
+			Where("product_id = ? AND on_hand + ? >= reserved", productID, adjustment.Delta).
+			Updates(map[string]interface{}{
+				"on_hand":    gorm.Expr("on_hand + ?", adjustment.Delta),
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInsufficientStock
+		}
+
+		if err = tx.Create(adjustment).Error; err != nil {
+			return err
+		}
+		inventory, err = NewInventory(tx).FindByProduct(ctx, productID)
+		return err
+	})
+	return inventory, err
+}
+
+// FindAdjustments returns the stock adjustments of a product, the latest first.
+func (i *Inventory) FindAdjustments(ctx context.Context, productID string, page, limit int) ([]entity.StockAdjustment, error) {
+	var adjustments []entity.StockAdjustment
+	query := i.DB.WithContext(ctx).Where("product_id = ?", productID).Order("created_at DESC").Order("id")
+	if page != 0 && limit != 0 {
+		query = query.Limit(limit).Offset((page - 1) * limit)
+	}
+	err := query.Find(&adjustments).Error
+	return adjustments, err
+}
+
+// Reserve holds the units of every item of reservation, or none of them. It
+// returns ErrInsufficientStock when a product doesn't have enough units available.
+func (i *Inventory) Reserve(ctx context.Context, reservation *entity.StockReservation) error {
+	return i.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, item := range reservation.Items {
+			result := tx.Model(&entity.Inventory{}).
+				Where("product_id = ? AND on_hand - reserved >= ?", item.ProductID.String(), item.Quantity).
+				Updates(map[string]interface{}{
+					"reserved":   gorm.Expr("reserved + ?", item.Quantity),
+					"updated_at": time.Now(),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("%w: product %s", ErrInsufficientStock, item.ProductID)
+			}
+		}
+		return tx.Create(reservation).Error
+	})
+}
+
+func (i *Inventory) FindReservation(ctx context.Context, id string) (*entity.StockReservation, error) {
+	var reservation entity.StockReservation
+	err := i.DB.WithContext(ctx).Preload("Items").Where("id = ?", id).First(&reservation).Error
+	return &reservation, err
+}
+
+// CommitReservation takes the units of a pending reservation out of stock,
+// recording a sale for each product. It returns ErrReservationExpired when
+// it expired and ErrReservationNotPending when it was already committed or released.
+func (i *Inventory) CommitReservation(ctx context.Context, id, userID string) (*entity.StockReservation, error) {
+	return i.finishReservation(ctx, id, entity.ReservationCommitted, func(tx *gorm.DB, reservation *entity.StockReservation, item entity.StockReservationItem) error {
+		err := tx.Model(&entity.Inventory{}).
+			Where("product_id = ?", item.ProductID.String()).
+			Updates(map[string]interface{}{
+				"on_hand":    gorm.Expr("on_hand - ?", item.Quantity),
+				"reserved":   gorm.Expr("reserved - ?", item.Quantity),
+				"updated_at": time.Now(),
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		adjustment, err := entity.NewStockAdjustment(item.ProductID, -item.Quantity, entity.StockReasonSale, "", userID)
+		if err != nil {
+			return err
+		}
+		adjustment.ReservationID = &reservation.ID
+		return tx.Create(adjustment).Error
+	})
+}
+
+// ReleaseReservation gives the units of a pending reservation back. It
+// returns ErrReservationNotPending when it was already committed, released or expired.
+func (i *Inventory) ReleaseReservation(ctx context.Context, id string) (*entity.StockReservation, error) {
+	return i.finishReservation(ctx, id, entity.ReservationReleased, releaseItem)
+}
+
+// ReleaseExpired gives back the units of the pending reservations that
+// expired before now and returns how many there were. Until then, expired
+// reservations can't be committed but still hold their units.
+func (i *Inventory) ReleaseExpired(ctx context.Context, now time.Time) (int64, error) {
+	var ids []string
+	err := i.DB.WithContext(ctx).Model(&entity.StockReservation{}).
+		Where("status = ? AND expires_at <= ?", entity.ReservationPending, now).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var released int64
+	for _, id := range ids {
+		_, err = i.finishReservation(ctx, id, entity.ReservationExpired, releaseItem)
+		if errors.Is(err, ErrReservationNotPending) {
+			// committed or released meanwhile
+			continue
+		}
+		if err != nil {
+			return released, err
+		}
+		released++
+	}
+	return released, nil
+}
+
+// finishReservation moves a pending reservation to status and calls apply
+// with each of its items in the same transaction. Only the request that
+// changes the status applies the items, so they are never applied twice.
+func (i *Inventory) finishReservation(ctx context.Context, id string, status entity.ReservationStatus, apply func(tx *gorm.DB, reservation *entity.StockReservation, item entity.StockReservationItem) error) (*entity.StockReservation, error) {
+	var reservation *entity.StockReservation
+	err := i.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		query := tx.Model(&entity.StockReservation{}).Where("id = ? AND status = ?", id, entity.ReservationPending)
+		if status != entity.ReservationExpired {
+			query = query.Where("expires_at > ?", now)
+		}
+		result := query.Updates(map[string]interface{}{"status": status, "updated_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		var err error
+		reservation, err = NewInventory(tx).FindReservation(ctx, id)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected == 0 {
+			if reservation.IsExpired(now) {
+				return ErrReservationExpired
+			}
+			return ErrReservationNotPending
+		}
+
+		for _, item := range reservation.Items {
+			if err = apply(tx, reservation, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return reservation, err
+}
+
+func releaseItem(tx *gorm.DB, _ *entity.StockReservation, item entity.StockReservationItem) error {
+	return tx.Model(&entity.Inventory{}).
+		Where("product_id = ?", item.ProductID.String()).
+		Updates(map[string]interface{}{
+			"reserved":   gorm.Expr("reserved - ?", item.Quantity),
+			"updated_at": time.Now(),
+		}).Error
+}
@@ -0,0 +1,225 @@
+package database
+
+import (
+	"context"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database/migrations"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func restock(t *testing.T, inventoryDB *Inventory, productID entity2.ID, delta int64) *entity.Inventory {
+	ctx := context.Background()
+	adjustment, err := entity.NewStockAdjustment(productID, delta, entity.StockReasonRestock, "", "")
+	assert.Nil(t, err)
+	inventory, err := inventoryDB.Adjust(ctx, adjustment)
+	assert.Nil(t, err)
+	return inventory
+}
+
+func reserve(t *testing.T, productID entity2.ID, quantity int64, ttl time.Duration) *entity.StockReservation {
+	reservation, err := entity.NewStockReservation([]entity.StockReservationItem{{ProductID: productID, Quantity: quantity}}, ttl)
+	assert.Nil(t, err)
+	return reservation
+}
+
+func TestInventory_Adjust(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	inventoryDB := NewInventory(db)
+	productID := entity2.NewID()
+
+	inventory, err := inventoryDB.FindByProduct(ctx, productID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, productID, inventory.ProductID)
+	assert.Equal(t, int64(0), inventory.OnHand)
+
+	inventory = restock(t, inventoryDB, productID, 10)
+	assert.Equal(t, int64(10), inventory.OnHand)
+
+	damage, err := entity.NewStockAdjustment(productID, -4, entity.StockReasonDamage, "broken box", "user")
+	assert.Nil(t, err)
+	inventory, err = inventoryDB.Adjust(ctx, damage)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(6), inventory.OnHand)
+
+	assert.Nil(t, inventoryDB.Reserve(ctx, reserve(t, productID, 5, time.Minute)))
+	tooMuch, err := entity.NewStockAdjustment(productID, -2, entity.StockReasonCorrection, "", "user")
+	assert.Nil(t, err)
+	_, err = inventoryDB.Adjust(ctx, tooMuch)
+	assert.ErrorIs(t, err, ErrInsufficientStock)
+
+	adjustments, err := inventoryDB.FindAdjustments(ctx, productID.String(), 0, 0)
+	assert.Nil(t, err)
+	assert.Len(t, adjustments, 2)
+	assert.Equal(t, entity.StockReasonDamage, adjustments[0].Reason)
+	assert.Equal(t, "broken box", adjustments[0].Note)
+}
+
+func TestInventory_ReserveAndCommit(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	inventoryDB := NewInventory(db)
+	laptop, mouse := entity2.NewID(), entity2.NewID()
+	restock(t, inventoryDB, laptop, 2)
+	restock(t, inventoryDB, mouse, 1)
+
+	reservation, err := entity.NewStockReservation([]entity.StockReservationItem{
+		{ProductID: laptop, Quantity: 2},
+		{ProductID: mouse, Quantity: 2},
+	}, time.Minute)
+	assert.Nil(t, err)
+	assert.ErrorIs(t, inventoryDB.Reserve(ctx, reservation), ErrInsufficientStock)
+	inventory, _ := inventoryDB.FindByProduct(ctx, laptop.String())
+	assert.Equal(t, int64(0), inventory.Reserved)
+
+	reservation = reserve(t, laptop, 2, time.Minute)
+	assert.Nil(t, inventoryDB.Reserve(ctx, reservation))
+	inventory, _ = inventoryDB.FindByProduct(ctx, laptop.String())
+	assert.Equal(t, int64(2), inventory.Reserved)
+	assert.Equal(t, int64(0), inventory.Available())
+
+	committed, err := inventoryDB.CommitReservation(ctx, reservation.ID.String(), "user")
+	assert.Nil(t, err)
+	assert.Equal(t, entity.ReservationCommitted, committed.Status)
+	assert.Len(t, committed.Items, 1)
+	inventory, _ = inventoryDB.FindByProduct(ctx, laptop.String())
+	assert.Equal(t, int64(0), inventory.OnHand)
+	assert.Equal(t, int64(0), inventory.Reserved)
+
+	_, err = inventoryDB.CommitReservation(ctx, reservation.ID.String(), "user")
+	assert.ErrorIs(t, err, ErrReservationNotPending)
+	_, err = inventoryDB.ReleaseReservation(ctx, reservation.ID.String())
+	assert.ErrorIs(t, err, ErrReservationNotPending)
+	_, err = inventoryDB.CommitReservation(ctx, entity2.NewID().String(), "user")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	adjustments, _ := inventoryDB.FindAdjustments(ctx, laptop.String(), 0, 0)
+	assert.Equal(t, entity.StockReasonSale, adjustments[0].Reason)
+	assert.Equal(t, int64(-2), adjustments[0].Delta)
+	assert.Equal(t, reservation.ID, *adjustments[0].ReservationID)
+}
+
+func TestInventory_ReleaseAndExpire(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	inventoryDB := NewInventory(db)
+	productID := entity2.NewID()
+	restock(t, inventoryDB, productID, 5)
+
+	released := reserve(t, productID, 2, time.Minute)
+	assert.Nil(t, inventoryDB.Reserve(ctx, released))
+	expiring := reserve(t, productID, 3, time.Second)
+	assert.Nil(t, inventoryDB.Reserve(ctx, expiring))
+
+	reservation, err := inventoryDB.ReleaseReservation(ctx, released.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, entity.ReservationReleased, reservation.Status)
+
+	db.Model(&entity.StockReservation{}).Where("id = ?", expiring.ID.String()).Update("expires_at", time.Now().Add(-time.Second))
+	_, err = inventoryDB.CommitReservation(ctx, expiring.ID.String(), "user")
+	assert.ErrorIs(t, err, ErrReservationExpired)
+
+	count, err := inventoryDB.ReleaseExpired(ctx, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	reservation, _ = inventoryDB.FindReservation(ctx, expiring.ID.String())
+	assert.Equal(t, entity.ReservationExpired, reservation.Status)
+	inventory, _ := inventoryDB.FindByProduct(ctx, productID.String())
+	assert.Equal(t, int64(5), inventory.OnHand)
+	assert.Equal(t, int64(0), inventory.Reserved)
+}
+
+// openFileDB opens a database in a file, as requests racing on an in-memory
+// one would each get their own empty database.
+func openFileDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")+"?_busy_timeout=10000&_txlock=immediate"), &gorm.Config{})
+	assert.Nil(t, err)
+	migrator, err := migrations.NewMigrator(db, migrations.All())
+	assert.Nil(t, err)
+	_, err = migrator.Up()
+	assert.Nil(t, err)
+	return db
+}
+
+func TestInventory_ConcurrentReservations(t *testing.T) {
+	ctx := context.Background()
+	db := openFileDB(t)
+
+	inventoryDB := NewInventory(db)
+	productID := entity2.NewID()
+	restock(t, inventoryDB, productID, 10)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded, insufficient := 0, 0
+	for n := 0; n < 25; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reservation, _ := entity.NewStockReservation([]entity.StockReservationItem{{ProductID: productID, Quantity: 1}}, time.Minute)
+			err := inventoryDB.Reserve(ctx, reservation)
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				succeeded++
+			} else if assert.ErrorIs(t, err, ErrInsufficientStock) {
+				insufficient++
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, succeeded)
+	assert.Equal(t, 15, insufficient)
+	inventory, err := inventoryDB.FindByProduct(ctx, productID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), inventory.Reserved)
+}
+
+func TestInventory_ConcurrentCommits(t *testing.T) {
+	ctx := context.Background()
+	db := openFileDB(t)
+
+	inventoryDB := NewInventory(db)
+	productID := entity2.NewID()
+	restock(t, inventoryDB, productID, 3)
+	reservation := reserve(t, productID, 3, time.Minute)
+	assert.Nil(t, inventoryDB.Reserve(ctx, reservation))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	committed := 0
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := inventoryDB.CommitReservation(ctx, reservation.ID.String(), "user")
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				committed++
+			} else {
+				assert.ErrorIs(t, err, ErrReservationNotPending)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, committed)
+	inventory, err := inventoryDB.FindByProduct(ctx, productID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), inventory.OnHand)
+	assert.Equal(t, int64(0), inventory.Reserved)
+}
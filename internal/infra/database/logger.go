@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/logging"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"log/slog"
+	"time"
+)
+
+// slowQuery is how long a query takes before it is logged as a warning.
+const slowQuery = 200 * time.Millisecond
+
+// queryLogger logs the queries through the logger of their context, so they
+// carry the ID of the request that made them. Failed queries are errors, slow
+// ones warnings and the others debug messages.
+type queryLogger struct {
+	mode logger.LogLevel
+}
+
+func newQueryLogger() logger.Interface {
+	return &queryLogger{mode: logger.Info}
+}
+
+func (l *queryLogger) LogMode(mode logger.LogLevel) logger.Interface {
+	return &queryLogger{mode: mode}
+}
+
+func (l *queryLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	l.log(ctx, logger.Info, slog.LevelInfo, fmt.Sprintf(msg, args...))
+}
+
+func (l *queryLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	l.log(ctx, logger.Warn, slog.LevelWarn, fmt.Sprintf(msg, args...))
+}
+
+func (l *queryLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	l.log(ctx, logger.Error, slog.LevelError, fmt.Sprintf(msg, args...))
+}
+
+func (l *queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.mode == logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	level := slog.LevelDebug
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		level = slog.LevelError
+	case elapsed > slowQuery:
+		level = slog.LevelWarn
+	}
+
+	log := logging.FromContext(ctx)
+	if !log.Enabled(ctx, level) {
+		return
+	}
+	sql, rows := fc()
+	attrs := []any{"sql", sql, "rows", rows, "duration_ms", elapsed.Milliseconds()}
+	if level == slog.LevelError {
+		attrs = append(attrs, "error", err)
+	}
+	log.Log(ctx, level, "query", attrs...)
+}
+
+func (l *queryLogger) log(ctx context.Context, mode logger.LogLevel, level slog.Level, msg string) {
+	if l.mode >= mode {
+		logging.FromContext(ctx).Log(ctx, level, msg)
+	}
+}
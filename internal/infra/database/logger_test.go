@@ -0,0 +1,35 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/logging"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueryLogger_Trace(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := logging.New(&out, "info")
+	assert.Nil(t, err)
+	ctx := logging.WithContext(context.Background(), logger.With("request_id", "abc"))
+	query := func() (string, int64) { return "SELECT 1", 1 }
+
+	l := newQueryLogger()
+	l.Trace(ctx, time.Now(), query, nil)
+	l.Trace(ctx, time.Now(), query, gorm.ErrRecordNotFound)
+	assert.Empty(t, out.String())
+
+	l.Trace(ctx, time.Now().Add(-time.Second), query, nil)
+	l.Trace(ctx, time.Now(), query, errors.New("no such table"))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"level":"WARN"`)
+	assert.Contains(t, lines[0], `"request_id":"abc"`)
+	assert.Contains(t, lines[1], `"level":"ERROR"`)
+	assert.Contains(t, lines[1], `"error":"no such table"`)
+}
@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type product0001 struct {
+	ID          string `gorm:"primaryKey;size:36"`
+	Name        string
+	Description string
+	Price       float64
+	CreatedAt   time.Time
+}
+
+func (product0001) TableName() string { return "products" }
+
+var createProducts = Migration{
+	Version: 1,
+	Name:    "create_products",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&product0001{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&product0001{})
+	},
+}
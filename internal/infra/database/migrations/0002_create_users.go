@@ -0,0 +1,23 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type user0002 struct {
+	ID       string `gorm:"primaryKey;size:36"`
+	Name     string
+	Email    string
+	Password string
+}
+
+func (user0002) TableName() string { return "users" }
+
+var createUsers = Migration{
+	Version: 2,
+	Name:    "create_users",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&user0002{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&user0002{})
+	},
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type refreshToken0003 struct {
+	ID        string    `gorm:"primaryKey;size:36"`
+	UserID    string    `gorm:"size:36;not null;index"`
+	FamilyID  string    `gorm:"size:36;not null;index"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+func (refreshToken0003) TableName() string { return "refresh_tokens" }
+
+var createRefreshTokens = Migration{
+	Version: 3,
+	Name:    "create_refresh_tokens",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&refreshToken0003{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&refreshToken0003{})
+	},
+}
@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type revokedToken0004 struct {
+	JTI       string    `gorm:"primaryKey;size:36"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+func (revokedToken0004) TableName() string { return "revoked_tokens" }
+
+var createRevokedTokens = Migration{
+	Version: 4,
+	Name:    "create_revoked_tokens",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&revokedToken0004{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&revokedToken0004{})
+	},
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type user0005 struct {
+	ID       string `gorm:"primaryKey;size:36"`
+	Name     string
+	Email    string
+	Password string
+	Role     string `gorm:"size:20;not null;default:viewer"`
+}
+
+func (user0005) TableName() string { return "users" }
+
+var addRoleToUsers = Migration{
+	Version: 5,
+	Name:    "add_role_to_users",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&user0005{}, "Role"); err != nil {
+			return err
+		}
+		// users created before roles existed could change every product, keep that access
+		return tx.Model(&user0005{}).Where("1 = 1").Update("role", "editor").Error
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(&user0005{}, "Role")
+	},
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type product0006 struct {
+	ID          string `gorm:"primaryKey;size:36"`
+	Name        string
+	Description string
+	Price       float64
+	OwnerID     *string `gorm:"size:36;index"`
+	CreatedAt   time.Time
+}
+
+func (product0006) TableName() string { return "products" }
+
+var addOwnerToProducts = Migration{
+	Version: 6,
+	Name:    "add_owner_to_products",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&product0006{}, "OwnerID"); err != nil {
+			return err
+		}
+		return tx.Migrator().CreateIndex(&product0006{}, "OwnerID")
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropIndex(&product0006{}, "OwnerID"); err != nil {
+			return err
+		}
+		return tx.Migrator().DropColumn(&product0006{}, "OwnerID")
+	},
+}
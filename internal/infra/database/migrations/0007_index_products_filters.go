@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type product0007 struct {
+	ID          string `gorm:"primaryKey;size:36"`
+	Name        string
+	Description string
+	Price       float64   `gorm:"index"`
+	OwnerID     *string   `gorm:"size:36;index"`
+	CreatedAt   time.Time `gorm:"index"`
+}
+
+func (product0007) TableName() string { return "products" }
+
+var indexProductsFilters = Migration{
+	Version: 7,
+	Name:    "index_products_filters",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.Migrator().CreateIndex(&product0007{}, "Price"); err != nil {
+			return err
+		}
+		return tx.Migrator().CreateIndex(&product0007{}, "CreatedAt")
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropIndex(&product0007{}, "Price"); err != nil {
+			return err
+		}
+		return tx.Migrator().DropIndex(&product0007{}, "CreatedAt")
+	},
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type product0008 struct {
+	ID          string `gorm:"primaryKey;size:36"`
+	Name        string
+	Description string
+	Price       float64    `gorm:"index"`
+	OwnerID     *string    `gorm:"size:36;index"`
+	CreatedAt   time.Time  `gorm:"index"`
+	DeletedAt   *time.Time `gorm:"index"`
+}
+
+func (product0008) TableName() string { return "products" }
+
+var addDeletedAtToProducts = Migration{
+	Version: 8,
+	Name:    "add_deleted_at_to_products",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&product0008{}, "DeletedAt"); err != nil {
+			return err
+		}
+		return tx.Migrator().CreateIndex(&product0008{}, "DeletedAt")
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropIndex(&product0008{}, "DeletedAt"); err != nil {
+			return err
+		}
+		return dropColumn(tx, "products", "deleted_at")
+	},
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type product0009 struct {
+	ID          string `gorm:"primaryKey;size:36"`
+	Name        string
+	Description string
+	Price       float64    `gorm:"index"`
+	OwnerID     *string    `gorm:"size:36;index"`
+	Version     int        `gorm:"not null;default:1"`
+	CreatedAt   time.Time  `gorm:"index"`
+	DeletedAt   *time.Time `gorm:"index"`
+}
+
+func (product0009) TableName() string { return "products" }
+
+var addVersionToProducts = Migration{
+	Version: 9,
+	Name:    "add_version_to_products",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().AddColumn(&product0009{}, "Version")
+	},
+	Down: func(tx *gorm.DB) error {
+		return dropColumn(tx, "products", "version")
+	},
+}
@@ -0,0 +1,87 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"time"
+)
+
+// ErrNonUSDPrices stops reverting storeProductPricesAsMoney, as the plain
+// price it goes back to can only hold USD amounts in cents.
+var ErrNonUSDPrices = errors.New("products priced in other currencies than USD can't be reverted")
+
+type product0010 struct {
+	ID            string `gorm:"primaryKey;size:36"`
+	Name          string
+	Description   string
+	PriceAmount   int64      `gorm:"not null;default:0;index"`
+	PriceCurrency string     `gorm:"size:3;not null;default:USD"`
+	OwnerID       *string    `gorm:"size:36;index"`
+	Version       int        `gorm:"not null;default:1"`
+	CreatedAt     time.Time  `gorm:"index"`
+	DeletedAt     *time.Time `gorm:"index"`
+}
+
+func (product0010) TableName() string { return "products" }
+
+// storeProductPricesAsMoney replaces the float price with its amount in minor
+// units and a currency. Existing prices are taken as USD, so they are
+// rounded to cents. It can only be reverted while every price is in USD.
+var storeProductPricesAsMoney = Migration{
+	Version: 10,
+	Name:    "store_product_prices_as_money",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&product0010{}, "PriceAmount"); err != nil {
+			return err
+		}
+		if err := tx.Migrator().AddColumn(&product0010{}, "PriceCurrency"); err != nil {
+			return err
+		}
+		if err := tx.Exec(priceAmountSQL(tx.Dialector.Name())).Error; err != nil {
+			return err
+		}
+		if err := tx.Migrator().DropIndex(&product0009{}, "Price"); err != nil {
+			return err
+		}
+		if err := dropColumn(tx, "products", "price"); err != nil {
+			return err
+		}
+		return tx.Migrator().CreateIndex(&product0010{}, "PriceAmount")
+	},
+	Down: func(tx *gorm.DB) error {
+		var nonUSD int64
+		if err := tx.Model(&product0010{}).Where("price_currency <> ?", "USD").Count(&nonUSD).Error; err != nil {
+			return err
+		}
+		if nonUSD > 0 {
+			return fmt.Errorf("%w: %d products", ErrNonUSDPrices, nonUSD)
+		}
+		if err := tx.Migrator().AddColumn(&product0009{}, "Price"); err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE products SET price = price_amount / 100.0").Error; err != nil {
+			return err
+		}
+		if err := tx.Migrator().DropIndex(&product0010{}, "PriceAmount"); err != nil {
+			return err
+		}
+		if err := dropColumn(tx, "products", "price_amount"); err != nil {
+			return err
+		}
+		if err := dropColumn(tx, "products", "price_currency"); err != nil {
+			return err
+		}
+		return tx.Migrator().CreateIndex(&product0009{}, "Price")
+	},
+}
+
+// priceAmountSQL returns the statement converting the prices for dialect, as
+// MySQL can only cast to SIGNED where the others take BIGINT.
+func priceAmountSQL(dialect string) string {
+	integer := "BIGINT"
+	if dialect == "mysql" {
+		integer = "SIGNED"
+	}
+	return "UPDATE products SET price_amount = CAST(ROUND(price * 100) AS " + integer + "), price_currency = 'USD'"
+}
@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type exchangeRate0011 struct {
+	Currency          string `gorm:"primaryKey;size:3"`
+	Rate              string `gorm:"size:32;not null"`
+	RoundingMode      string `gorm:"size:16;not null;default:half_up"`
+	RoundingIncrement int64  `gorm:"not null;default:1"`
+	UpdatedAt         time.Time
+}
+
+func (exchangeRate0011) TableName() string { return "exchange_rates" }
+
+var createExchangeRates = Migration{
+	Version: 11,
+	Name:    "create_exchange_rates",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&exchangeRate0011{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&exchangeRate0011{})
+	},
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type category0012 struct {
+	ID        string  `gorm:"primaryKey;size:36"`
+	Name      string  `gorm:"not null"`
+	ParentID  *string `gorm:"size:36;index"`
+	CreatedAt time.Time
+}
+
+func (category0012) TableName() string { return "categories" }
+
+type productCategory0012 struct {
+	ProductID  string `gorm:"primaryKey;size:36"`
+	CategoryID string `gorm:"primaryKey;size:36;index"`
+}
+
+func (productCategory0012) TableName() string { return "product_categories" }
+
+var createCategories = Migration{
+	Version: 12,
+	Name:    "create_categories",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&category0012{}, &productCategory0012{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&productCategory0012{}, &category0012{})
+	},
+}
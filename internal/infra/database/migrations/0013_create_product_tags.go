@@ -0,0 +1,21 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type productTag0013 struct {
+	ProductID string `gorm:"primaryKey;size:36"`
+	Tag       string `gorm:"primaryKey;size:50;index"`
+}
+
+func (productTag0013) TableName() string { return "product_tags" }
+
+var createProductTags = Migration{
+	Version: 13,
+	Name:    "create_product_tags",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&productTag0013{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&productTag0013{})
+	},
+}
@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type inventory0014 struct {
+	ProductID string `gorm:"primaryKey;size:36"`
+	OnHand    int64  `gorm:"not null;default:0"`
+	Reserved  int64  `gorm:"not null;default:0"`
+	UpdatedAt time.Time
+}
+
+func (inventory0014) TableName() string { return "inventories" }
+
+type stockAdjustment0014 struct {
+	ID            string `gorm:"primaryKey;size:36"`
+	ProductID     string `gorm:"size:36;not null;index"`
+	Delta         int64  `gorm:"not null"`
+	Reason        string `gorm:"size:20;not null"`
+	Note          string
+	UserID        string  `gorm:"size:36"`
+	ReservationID *string `gorm:"size:36"`
+	CreatedAt     time.Time
+}
+
+func (stockAdjustment0014) TableName() string { return "stock_adjustments" }
+
+type stockReservation0014 struct {
+	ID        string    `gorm:"primaryKey;size:36"`
+	Status    string    `gorm:"size:20;not null;index:idx_stock_reservations_status_expires_at"`
+	ExpiresAt time.Time `gorm:"index:idx_stock_reservations_status_expires_at"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (stockReservation0014) TableName() string { return "stock_reservations" }
+
+type stockReservationItem0014 struct {
+	ReservationID string `gorm:"primaryKey;size:36"`
+	ProductID     string `gorm:"primaryKey;size:36;index"`
+	Quantity      int64  `gorm:"not null"`
+}
+
+func (stockReservationItem0014) TableName() string { return "stock_reservation_items" }
+
+var createInventory = Migration{
+	Version: 14,
+	Name:    "create_inventory",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&inventory0014{}, &stockAdjustment0014{}, &stockReservation0014{}, &stockReservationItem0014{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&stockReservationItem0014{}, &stockReservation0014{}, &stockAdjustment0014{}, &inventory0014{})
+	},
+}
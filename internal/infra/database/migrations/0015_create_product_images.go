@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type productImage0015 struct {
+	ID           string `gorm:"primaryKey;size:36"`
+	ProductID    string `gorm:"size:36;not null;index"`
+	ContentType  string `gorm:"size:50;not null"`
+	Size         int64  `gorm:"not null"`
+	Width        int    `gorm:"not null"`
+	Height       int    `gorm:"not null"`
+	Key          string `gorm:"size:255;not null"`
+	ThumbnailKey string `gorm:"size:255;not null"`
+	URL          string `gorm:"size:2048;not null"`
+	ThumbnailURL string `gorm:"size:2048;not null"`
+	CreatedAt    time.Time
+}
+
+func (productImage0015) TableName() string { return "product_images" }
+
+var createProductImages = Migration{
+	Version: 15,
+	Name:    "create_product_images",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&productImage0015{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&productImage0015{})
+	},
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type variant0016 struct {
+	ID            string `gorm:"primaryKey;size:36"`
+	ProductID     string `gorm:"size:36;not null;index"`
+	SKU           string `gorm:"column:sku;size:64;not null;uniqueIndex"`
+	Options       string `gorm:"type:text;not null"`
+	PriceAmount   *int64
+	PriceCurrency *string `gorm:"size:3"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (variant0016) TableName() string { return "variants" }
+
+var createVariants = Migration{
+	Version: 16,
+	Name:    "create_variants",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&variant0016{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&variant0016{})
+	},
+}
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type priceChange0017 struct {
+	ID               string    `gorm:"primaryKey;size:36"`
+	ProductID        string    `gorm:"size:36;not null;index:idx_price_history_product_id_created_at"`
+	PriceAmount      int64     `gorm:"not null"`
+	PriceCurrency    string    `gorm:"size:3;not null"`
+	ChangedBy        string    `gorm:"size:36"`
+	ScheduledPriceID *string   `gorm:"size:36"`
+	CreatedAt        time.Time `gorm:"index:idx_price_history_product_id_created_at"`
+}
+
+func (priceChange0017) TableName() string { return "price_history" }
+
+type scheduledPrice0017 struct {
+	ID                   string    `gorm:"primaryKey;size:36"`
+	ProductID            string    `gorm:"size:36;not null;index"`
+	PriceAmount          int64     `gorm:"not null"`
+	PriceCurrency        string    `gorm:"size:3;not null"`
+	StartsAt             time.Time `gorm:"not null;index:idx_scheduled_prices_status_starts_at"`
+	EndsAt               *time.Time
+	Status               string `gorm:"size:20;not null;index:idx_scheduled_prices_status_starts_at"`
+	RestorePriceAmount   *int64
+	RestorePriceCurrency *string `gorm:"size:3"`
+	CreatedBy            string  `gorm:"size:36"`
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+}
+
+func (scheduledPrice0017) TableName() string { return "scheduled_prices" }
+
+// createPriceHistory also records the current price of every product as its
+// first change, made by its owner when it was created. The product id doubles
+// as the id of that change, as no other change can have it.
+var createPriceHistory = Migration{
+	Version: 17,
+	Name:    "create_price_history",
+	Up: func(tx *gorm.DB) error {
+		if err := tx.Migrator().CreateTable(&priceChange0017{}, &scheduledPrice0017{}); err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO price_history (id, product_id, price_amount, price_currency, changed_by, created_at)
+			SELECT id, id, price_amount, price_currency, COALESCE(owner_id, ''), created_at FROM products`).Error
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&scheduledPrice0017{}, &priceChange0017{})
+	},
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type auditEntry0018 struct {
+	ID           string    `gorm:"primaryKey;size:36"`
+	ActorID      string    `gorm:"size:36;index"`
+	Action       string    `gorm:"size:20;not null"`
+	ResourceType string    `gorm:"size:20;not null;index:idx_audit_log_resource"`
+	ResourceID   string    `gorm:"size:36;not null;index:idx_audit_log_resource"`
+	Before       *string   `gorm:"type:text"`
+	After        *string   `gorm:"type:text"`
+	RequestID    string    `gorm:"size:64;index"`
+	CreatedAt    time.Time `gorm:"index"`
+}
+
+func (auditEntry0018) TableName() string { return "audit_log" }
+
+var createAuditLog = Migration{
+	Version: 18,
+	Name:    "create_audit_log",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&auditEntry0018{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&auditEntry0018{})
+	},
+}
@@ -0,0 +1,13 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dropColumn runs ALTER TABLE DROP COLUMN. The sqlite gorm migrator recreates
+// the table instead, which loses every index created by earlier migrations.
+// Indexes on column itself must be dropped first.
+func dropColumn(tx *gorm.DB, table, column string) error {
+	return tx.Exec("ALTER TABLE ? DROP COLUMN ?", clause.Table{Name: table}, clause.Column{Name: column}).Error
+}
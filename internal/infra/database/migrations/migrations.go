@@ -0,0 +1,26 @@
+package migrations
+
+// All returns every schema migration of the application. New migrations must
+// be appended with the next version and never change once released.
+func All() []Migration {
+	return []Migration{
+		createProducts,
+		createUsers,
+		createRefreshTokens,
+		createRevokedTokens,
+		addRoleToUsers,
+		addOwnerToProducts,
+		indexProductsFilters,
+		addDeletedAtToProducts,
+		addVersionToProducts,
+		storeProductPricesAsMoney,
+		createExchangeRates,
+		createCategories,
+		createProductTags,
+		createInventory,
+		createProductImages,
+		createVariants,
+		createPriceHistory,
+		createAuditLog,
+	}
+}
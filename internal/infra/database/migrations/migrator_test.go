@@ -0,0 +1,270 @@
+package migrations
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func openDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.Nil(t, err)
+	return db
+}
+
+func migrationsUpTo(version int64) []Migration {
+	var migrations []Migration
+	for _, migration := range All() {
+		if migration.Version <= version {
+			migrations = append(migrations, migration)
+		}
+	}
+	return migrations
+}
+
+func TestMigrator_Up(t *testing.T) {
+	db := openDB(t)
+
+	migrator, err := NewMigrator(db, All())
+	assert.Nil(t, err)
+
+	applied, err := migrator.Up()
+	assert.Nil(t, err)
+	assert.Len(t, applied, len(All()))
+	assert.True(t, db.Migrator().HasTable("products"))
+	assert.True(t, db.Migrator().HasTable("users"))
+
+	applied, err = migrator.Up()
+	assert.Nil(t, err)
+	assert.Empty(t, applied)
+
+	pending, err := migrator.Pending()
+	assert.Nil(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestMigrator_Down(t *testing.T) {
+	db := openDB(t)
+
+	migrator, err := NewMigrator(db, All())
+	assert.Nil(t, err)
+	_, err = migrator.Up()
+	assert.Nil(t, err)
+
+	rolledBack, err := migrator.Down(1)
+	assert.Nil(t, err)
+	assert.Len(t, rolledBack, 1)
+	all := All()
+	assert.Equal(t, all[len(all)-1].Version, rolledBack[0].Version)
+	assert.True(t, db.Migrator().HasTable("products"))
+
+	pending, err := migrator.Pending()
+	assert.Nil(t, err)
+	assert.Len(t, pending, 1)
+
+	rolledBack, err = migrator.Down(len(All()))
+	assert.Nil(t, err)
+	assert.Len(t, rolledBack, len(All())-1)
+	assert.False(t, db.Migrator().HasTable("products"))
+	assert.False(t, db.Migrator().HasTable("users"))
+}
+
+func TestMigrator_Status(t *testing.T) {
+	db := openDB(t)
+
+	migrator, err := NewMigrator(db, []Migration{createUsers, createProducts})
+	assert.Nil(t, err)
+
+	statuses, err := migrator.Status()
+	assert.Nil(t, err)
+	assert.Len(t, statuses, 2)
+	assert.Equal(t, createProducts.Version, statuses[0].Version)
+	assert.Nil(t, statuses[0].AppliedAt)
+
+	_, err = migrator.Up()
+	assert.Nil(t, err)
+
+	statuses, err = migrator.Status()
+	assert.Nil(t, err)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.NotNil(t, statuses[1].AppliedAt)
+}
+
+func TestMigrator_UpRollsBackFailedMigration(t *testing.T) {
+	db := openDB(t)
+
+	failing := Migration{
+		Version: 3,
+		Name:    "failing",
+		Up: func(tx *gorm.DB) error {
+			return errors.New("boom")
+		},
+		Down: func(tx *gorm.DB) error { return nil },
+	}
+
+	migrator, err := NewMigrator(db, []Migration{createProducts, failing})
+	assert.Nil(t, err)
+
+	applied, err := migrator.Up()
+	assert.Error(t, err)
+	assert.Len(t, applied, 1)
+
+	pending, err := migrator.Pending()
+	assert.Nil(t, err)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, failing.Version, pending[0].Version)
+}
+
+func TestNewMigrator_DuplicatedVersion(t *testing.T) {
+	_, err := NewMigrator(openDB(t), []Migration{createProducts, createProducts})
+	assert.ErrorIs(t, err, ErrDuplicatedVersion)
+}
+
+func TestAddRoleToUsers_KeepsExistingUsersAsEditors(t *testing.T) {
+	db := openDB(t)
+
+	migrator, err := NewMigrator(db, []Migration{createProducts, createUsers, createRefreshTokens, createRevokedTokens})
+	assert.Nil(t, err)
+	_, err = migrator.Up()
+	assert.Nil(t, err)
+
+	assert.Nil(t, db.Create(&user0002{ID: "1", Name: "John Doe", Email: "j@j.com"}).Error)
+
+	migrator, err = NewMigrator(db, All())
+	assert.Nil(t, err)
+	_, err = migrator.Up()
+	assert.Nil(t, err)
+
+	var existing user0005
+	assert.Nil(t, db.First(&existing, "id = ?", "1").Error)
+	assert.Equal(t, "editor", existing.Role)
+
+	assert.Nil(t, db.Create(&user0002{ID: "2", Name: "Jane Doe", Email: "jane@j.com"}).Error)
+	var created user0005
+	assert.Nil(t, db.First(&created, "id = ?", "2").Error)
+	assert.Equal(t, "viewer", created.Role)
+}
+
+func TestMigrator_DownAndUpAgain(t *testing.T) {
+	db := openDB(t)
+
+	migrator, err := NewMigrator(db, All())
+	assert.Nil(t, err)
+
+	for i := 0; i < 2; i++ {
+		applied, err := migrator.Up()
+		assert.Nil(t, err)
+		assert.Len(t, applied, len(All()))
+
+		rolledBack, err := migrator.Down(len(All()))
+		assert.Nil(t, err)
+		assert.Len(t, rolledBack, len(All()))
+	}
+}
+
+func TestMigrator_DownKeepsEarlierIndexes(t *testing.T) {
+	db := openDB(t)
+
+	migrator, err := NewMigrator(db, []Migration{createProducts, addOwnerToProducts, indexProductsFilters, addDeletedAtToProducts})
+	assert.Nil(t, err)
+	_, err = migrator.Up()
+	assert.Nil(t, err)
+
+	_, err = migrator.Down(1)
+	assert.Nil(t, err)
+	assert.True(t, db.Migrator().HasIndex(&product0007{}, "Price"))
+	assert.True(t, db.Migrator().HasIndex(&product0007{}, "CreatedAt"))
+	assert.True(t, db.Migrator().HasIndex(&product0007{}, "OwnerID"))
+}
+
+func TestStoreProductPricesAsMoney_ConvertsExistingPrices(t *testing.T) {
+	db := openDB(t)
+
+	migrator, err := NewMigrator(db, migrationsUpTo(addVersionToProducts.Version))
+	assert.Nil(t, err)
+	_, err = migrator.Up()
+	assert.Nil(t, err)
+
+	assert.Nil(t, db.Create(&product0009{ID: "1", Name: "Laptop", Price: 1099.99, Version: 1}).Error)
+
+	migrator, err = NewMigrator(db, migrationsUpTo(storeProductPricesAsMoney.Version))
+	assert.Nil(t, err)
+	_, err = migrator.Up()
+	assert.Nil(t, err)
+
+	var converted product0010
+	assert.Nil(t, db.First(&converted, "id = ?", "1").Error)
+	assert.Equal(t, int64(109999), converted.PriceAmount)
+	assert.Equal(t, "USD", converted.PriceCurrency)
+	assert.True(t, db.Migrator().HasIndex(&product0010{}, "PriceAmount"))
+
+	assert.Nil(t, db.Create(&product0010{ID: "2", Name: "Camera", PriceAmount: 150000, PriceCurrency: "JPY", Version: 1}).Error)
+	assert.Nil(t, db.Create(&product0010{ID: "3", Name: "Mouse", PriceAmount: 2999, PriceCurrency: "EUR", Version: 1}).Error)
+
+	_, err = migrator.Down(1)
+	assert.ErrorIs(t, err, ErrNonUSDPrices)
+	var kept product0010
+	assert.Nil(t, db.First(&kept, "id = ?", "2").Error)
+	assert.Equal(t, int64(150000), kept.PriceAmount)
+	assert.Equal(t, "JPY", kept.PriceCurrency)
+
+	assert.Nil(t, db.Delete(&product0010{}, "id IN ?", []string{"2", "3"}).Error)
+	_, err = migrator.Down(1)
+	assert.Nil(t, err)
+
+	var restored product0009
+	assert.Nil(t, db.First(&restored, "id = ?", "1").Error)
+	assert.Equal(t, 1099.99, restored.Price)
+	assert.True(t, db.Migrator().HasIndex(&product0009{}, "Price"))
+}
+
+func TestStoreProductPricesAsMoney_SQLPerDialect(t *testing.T) {
+	casts := map[string]string{"sqlite": "AS BIGINT", "postgres": "AS BIGINT", "mysql": "AS SIGNED"}
+	for dialect, cast := range casts {
+		sql := priceAmountSQL(dialect)
+		assert.Contains(t, sql, cast, dialect)
+
+		// sqlite takes both casts, so each statement is run against it
+		db := openDB(t)
+		migrator, err := NewMigrator(db, migrationsUpTo(addVersionToProducts.Version))
+		assert.Nil(t, err)
+		_, err = migrator.Up()
+		assert.Nil(t, err)
+		assert.Nil(t, db.Create(&product0009{ID: "1", Name: "Laptop", Price: 1099.99, Version: 1}).Error)
+		assert.Nil(t, db.Migrator().AddColumn(&product0010{}, "PriceAmount"))
+		assert.Nil(t, db.Migrator().AddColumn(&product0010{}, "PriceCurrency"))
+
+		assert.Nil(t, db.Exec(sql).Error, dialect)
+		var converted product0010
+		assert.Nil(t, db.First(&converted, "id = ?", "1").Error)
+		assert.Equal(t, int64(109999), converted.PriceAmount, dialect)
+	}
+}
+
+func TestCreatePriceHistory_RecordsExistingPrices(t *testing.T) {
+	db := openDB(t)
+
+	migrator, err := NewMigrator(db, migrationsUpTo(createVariants.Version))
+	assert.Nil(t, err)
+	_, err = migrator.Up()
+	assert.Nil(t, err)
+
+	owner := "owner"
+	assert.Nil(t, db.Create(&product0010{ID: "1", Name: "Laptop", PriceAmount: 109999, PriceCurrency: "USD", OwnerID: &owner, Version: 1}).Error)
+	assert.Nil(t, db.Create(&product0010{ID: "2", Name: "Mouse", PriceAmount: 2999, PriceCurrency: "EUR", Version: 1}).Error)
+
+	migrator, err = NewMigrator(db, migrationsUpTo(createPriceHistory.Version))
+	assert.Nil(t, err)
+	_, err = migrator.Up()
+	assert.Nil(t, err)
+
+	var changes []priceChange0017
+	assert.Nil(t, db.Order("product_id").Find(&changes).Error)
+	assert.Len(t, changes, 2)
+	assert.Equal(t, int64(109999), changes[0].PriceAmount)
+	assert.Equal(t, "owner", changes[0].ChangedBy)
+	assert.Equal(t, "EUR", changes[1].PriceCurrency)
+	assert.Equal(t, "", changes[1].ChangedBy)
+}
@@ -0,0 +1,241 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	ErrScheduleOverlap    = errors.New("the schedule overlaps another scheduled price of the product")
+	ErrScheduleNotPending = errors.New("only pending scheduled prices can be canceled")
+)
+
+// errScheduleChanged rolls back a scheduled price whose status was changed
+// while it was being applied, like canceled.
+var errScheduleChanged = errors.New("scheduled price was changed meanwhile")
+
+type Price struct {
+	DB *gorm.DB
+}
+
+func NewPrice(db *gorm.DB) *Price {
+	return &Price{DB: db}
+}
+
+// FindHistory returns the price changes of a product, the latest first.
+func (p *Price) FindHistory(ctx context.Context, productID string, page, limit int) ([]entity.PriceChange, error) {
+	var changes []entity.PriceChange
+	query := p.DB.WithContext(ctx).Where("product_id = ?", productID).Order("created_at DESC").Order("id")
+	if page != 0 && limit != 0 {
+		query = query.Limit(limit).Offset((page - 1) * limit)
+	}
+	err := query.Find(&changes).Error
+	return changes, err
+}
+
+// Schedule stores scheduled. It returns ErrScheduleOverlap when it would be in
+// effect along with another pending or active scheduled price of the product.
+func (p *Price) Schedule(ctx context.Context, scheduled *entity.ScheduledPrice) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var others []entity.ScheduledPrice
+		err := tx.Where("product_id = ? AND status IN ?", scheduled.ProductID.String(),
+			[]entity.ScheduledPriceStatus{entity.ScheduledPricePending, entity.ScheduledPriceActive}).
+			Find(&others).Error
+		if err != nil {
+			return err
+		}
+		for i := range others {
+			if scheduled.Overlaps(&others[i]) {
+				return ErrScheduleOverlap
+			}
+		}
+		return tx.Create(scheduled).Error
+	})
+}
+
+// FindScheduled returns the scheduled prices of a product, the soonest first.
+func (p *Price) FindScheduled(ctx context.Context, productID string) ([]entity.ScheduledPrice, error) {
+	var scheduled []entity.ScheduledPrice
+	err := p.DB.WithContext(ctx).Where("product_id = ?", productID).Order("starts_at").Order("id").Find(&scheduled).Error
+	return scheduled, err
+}
+
+// FindScheduledByID finds a scheduled price of the product productID.
+func (p *Price) FindScheduledByID(ctx context.Context, productID, id string) (*entity.ScheduledPrice, error) {
+	var scheduled entity.ScheduledPrice
+	err := p.DB.WithContext(ctx).Where("id = ? AND product_id = ?", id, productID).First(&scheduled).Error
+	return &scheduled, err
+}
+
+// CancelScheduled cancels a scheduled price that didn't start yet. It returns
+// ErrScheduleNotPending when it did, or was already canceled.
+func (p *Price) CancelScheduled(ctx context.Context, productID, id string) (*entity.ScheduledPrice, error) {
+	result := p.DB.WithContext(ctx).Model(&entity.ScheduledPrice{}).
+		Where("id = ? AND product_id = ? AND status = ?", id, productID, entity.ScheduledPricePending).
+		Updates(map[string]interface{}{"status": entity.ScheduledPriceCanceled, "updated_at": time.Now()})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	scheduled, err := p.FindScheduledByID(ctx, productID, id)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrScheduleNotPending
+	}
+	return scheduled, nil
+}
+
+// ApplyScheduled gives products the scheduled prices effective at now, and
+// gives back the previous price to the products whose promotion ended,
+// returning how many scheduled prices were started or ended. Promotions end
+// first, so one starting when another ends restores the right price later.
+//
+// A promotion doesn't restore the previous price when the product's price was
+// changed while it ran. Products that were changed by a request while their
+// scheduled price was being applied are left for the next run.
+func (p *Price) ApplyScheduled(ctx context.Context, now time.Time) (int64, error) {
+	var ending []entity.ScheduledPrice
+	err := p.DB.WithContext(ctx).Where("status = ? AND ends_at <= ?", entity.ScheduledPriceActive, now).
+		Order("ends_at").Find(&ending).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var applied int64
+	for i := range ending {
+		err = p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return endScheduled(ctx, tx, &ending[i])
+		})
+		if err = skipChanged(err, &applied); err != nil {
+			return applied, err
+		}
+	}
+
+	var starting []entity.ScheduledPrice
+	err = p.DB.WithContext(ctx).Where("status = ? AND starts_at <= ?", entity.ScheduledPricePending, now).
+		Order("starts_at").Find(&starting).Error
+	if err != nil {
+		return applied, err
+	}
+	for i := range starting {
+		err = p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return startScheduled(ctx, tx, &starting[i], now)
+		})
+		if err = skipChanged(err, &applied); err != nil {
+			return applied, err
+		}
+	}
+	return applied, nil
+}
+
+// skipChanged counts a scheduled price as applied when err is nil, and
+// ignores the errors of those left for the next run.
+func skipChanged(err error, applied *int64) error {
+	switch {
+	case err == nil:
+		*applied++
+	case errors.Is(err, errScheduleChanged), errors.Is(err, ErrVersionConflict):
+	default:
+		return err
+	}
+	return nil
+}
+
+func startScheduled(ctx context.Context, tx *gorm.DB, scheduled *entity.ScheduledPrice, now time.Time) error {
+	if scheduled.EndsAt != nil && !scheduled.EndsAt.After(now) {
+		// the whole promotion went by while prices weren't applied
+		return setScheduledStatus(tx, scheduled, entity.ScheduledPricePending, entity.ScheduledPriceFinished, nil)
+	}
+
+	product, err := NewProduct(tx).FindByID(ctx, scheduled.ProductID.String())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return setScheduledStatus(tx, scheduled, entity.ScheduledPricePending, entity.ScheduledPriceCanceled, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	restorePrice := product.Price
+	if err = setScheduledPrice(ctx, tx, product, scheduled.Price, scheduled); err != nil {
+		return err
+	}
+	status := entity.ScheduledPriceFinished
+	if scheduled.EndsAt != nil {
+		status = entity.ScheduledPriceActive
+	}
+	return setScheduledStatus(tx, scheduled, entity.ScheduledPricePending, status, &restorePrice)
+}
+
+func endScheduled(ctx context.Context, tx *gorm.DB, scheduled *entity.ScheduledPrice) error {
+	product, err := NewProduct(tx).FindByID(ctx, scheduled.ProductID.String())
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil && scheduled.RestorePrice != nil && product.Price == scheduled.Price {
+		if err = setScheduledPrice(ctx, tx, product, *scheduled.RestorePrice, scheduled); err != nil {
+			return err
+		}
+	}
+	return setScheduledStatus(tx, scheduled, entity.ScheduledPriceActive, entity.ScheduledPriceFinished, nil)
+}
+
+// setScheduledPrice gives product price on behalf of scheduled, with the same
+// version check and audit as Product.Update.
+func setScheduledPrice(ctx context.Context, tx *gorm.DB, product *entity.Product, price entity2.Money, scheduled *entity.ScheduledPrice) error {
+	result := tx.Model(&entity.Product{}).
+		Where("id = ? AND version = ?", product.ID.String(), product.Version).
+		Updates(map[string]interface{}{
+			"price_amount":   price.Amount,
+			"price_currency": price.Currency,
+			"version":        product.Version + 1,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	if err := tx.Create(entity.NewPriceChange(product.ID, price, scheduled.CreatedBy, &scheduled.ID)).Error; err != nil {
+		return err
+	}
+
+	changed := *product
+	changed.Price = price
+	changed.Version++
+	return recordAudit(ctx, tx, entity.AuditUpdate, entity.AuditProduct, product.ID.String(), product, &changed)
+}
+
+// setScheduledStatus moves scheduled from the status from to to, storing the
+// price to restore when it is given. It returns errScheduleChanged when
+// scheduled is no longer in from.
+func setScheduledStatus(tx *gorm.DB, scheduled *entity.ScheduledPrice, from, to entity.ScheduledPriceStatus, restorePrice *entity2.Money) error {
+	values := map[string]interface{}{"status": to, "updated_at": time.Now()}
+	if restorePrice != nil {
+		values["restore_price_amount"] = restorePrice.Amount
+		values["restore_price_currency"] = restorePrice.Currency
+	}
+	result := tx.Model(&entity.ScheduledPrice{}).
+		Where("id = ? AND status = ?", scheduled.ID.String(), from).
+		Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errScheduleChanged
+	}
+	return nil
+}
+
+// recordPrice adds the price of product to its history when it differs from previous,
+// which is nil for a new product.
+func recordPrice(tx *gorm.DB, product *entity.Product, previous *entity2.Money, changedBy string) error {
+	if previous != nil && *previous == product.Price {
+		return nil
+	}
+	return tx.Create(entity.NewPriceChange(product.ID, product.Price, changedBy, nil)).Error
+}
@@ -0,0 +1,213 @@
+package database
+
+import (
+	"context"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func schedulePrice(t *testing.T, priceDB *Price, product *entity.Product, price entity2.Money, startsAt time.Time, endsAt *time.Time) *entity.ScheduledPrice {
+	ctx := context.Background()
+	scheduled, err := entity.NewScheduledPrice(product.ID, price, startsAt, endsAt, "user")
+	assert.Nil(t, err)
+	assert.Nil(t, priceDB.Schedule(ctx, scheduled))
+	return scheduled
+}
+
+func TestPrice_History(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+	productDB := NewProduct(db)
+	priceDB := NewPrice(db)
+
+	product, err := entity.NewProduct("Shirt", "", usd(2000))
+	assert.Nil(t, err)
+	product.OwnerID = entity2.NewID()
+	assert.Nil(t, productDB.Create(ctx, product))
+
+	product.Name = "Blue shirt"
+	assert.Nil(t, productDB.Update(WithActor(ctx, "editor"), product))
+	product.Price = usd(2500)
+	assert.Nil(t, productDB.Update(WithActor(ctx, "editor"), product))
+
+	changes, err := priceDB.FindHistory(ctx, product.ID.String(), 1, 10)
+	assert.Nil(t, err)
+	assert.Len(t, changes, 2)
+	assert.Equal(t, usd(2500), changes[0].Price)
+	assert.Equal(t, "editor", changes[0].ChangedBy)
+	assert.Nil(t, changes[0].ScheduledPriceID)
+	assert.Equal(t, usd(2000), changes[1].Price)
+	assert.Equal(t, product.OwnerID.String(), changes[1].ChangedBy)
+
+	product.Price = usd(3000)
+	product.Version--
+	assert.ErrorIs(t, productDB.Update(ctx, product), ErrVersionConflict)
+	changes, err = priceDB.FindHistory(ctx, product.ID.String(), 1, 1)
+	assert.Nil(t, err)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, usd(2500), changes[0].Price)
+}
+
+func TestPrice_Schedule(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+	productDB := NewProduct(db)
+	priceDB := NewPrice(db)
+
+	product, err := entity.NewProduct("Shirt", "", usd(2000))
+	assert.Nil(t, err)
+	assert.Nil(t, productDB.Create(ctx, product))
+
+	start := time.Now().Add(time.Hour)
+	end := start.Add(24 * time.Hour)
+	promotion := schedulePrice(t, priceDB, product, usd(1500), start, &end)
+
+	overlapping, err := entity.NewScheduledPrice(product.ID, usd(1000), start.Add(time.Hour), nil, "user")
+	assert.Nil(t, err)
+	assert.ErrorIs(t, priceDB.Schedule(ctx, overlapping), ErrScheduleOverlap)
+	afterwards := schedulePrice(t, priceDB, product, usd(2200), end, nil)
+
+	scheduled, err := priceDB.FindScheduled(ctx, product.ID.String())
+	assert.Nil(t, err)
+	assert.Len(t, scheduled, 2)
+	assert.Equal(t, promotion.ID, scheduled[0].ID)
+	assert.Equal(t, entity.ScheduledPricePending, scheduled[0].Status)
+
+	canceled, err := priceDB.CancelScheduled(ctx, product.ID.String(), afterwards.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, entity.ScheduledPriceCanceled, canceled.Status)
+	_, err = priceDB.CancelScheduled(ctx, product.ID.String(), afterwards.ID.String())
+	assert.ErrorIs(t, err, ErrScheduleNotPending)
+	_, err = priceDB.CancelScheduled(ctx, product.ID.String(), product.ID.String())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// canceled prices don't overlap anymore
+	schedulePrice(t, priceDB, product, usd(2200), end, nil)
+}
+
+func TestPrice_ApplyScheduled(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+	productDB := NewProduct(db)
+	priceDB := NewPrice(db)
+
+	product, err := entity.NewProduct("Shirt", "", usd(2000))
+	assert.Nil(t, err)
+	assert.Nil(t, productDB.Create(ctx, product))
+
+	start := time.Now().Add(time.Hour)
+	end := start.Add(24 * time.Hour)
+	promotion := schedulePrice(t, priceDB, product, usd(1500), start, &end)
+	raise := schedulePrice(t, priceDB, product, usd(2200), end.Add(time.Hour), nil)
+
+	applied, err := priceDB.ApplyScheduled(ctx, start.Add(-time.Second))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), applied)
+
+	applied, err = priceDB.ApplyScheduled(ctx, start)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), applied)
+	found, err := productDB.FindByID(ctx, product.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, usd(1500), found.Price)
+	assert.Equal(t, product.Version+1, found.Version)
+	scheduled, err := priceDB.FindScheduledByID(ctx, product.ID.String(), promotion.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, entity.ScheduledPriceActive, scheduled.Status)
+
+	applied, err = priceDB.ApplyScheduled(ctx, end)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), applied)
+	found, err = productDB.FindByID(ctx, product.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, usd(2000), found.Price)
+	scheduled, err = priceDB.FindScheduledByID(ctx, product.ID.String(), promotion.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, entity.ScheduledPriceFinished, scheduled.Status)
+
+	applied, err = priceDB.ApplyScheduled(ctx, end.Add(2*time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), applied)
+	found, err = productDB.FindByID(ctx, product.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, usd(2200), found.Price)
+	scheduled, err = priceDB.FindScheduledByID(ctx, product.ID.String(), raise.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, entity.ScheduledPriceFinished, scheduled.Status)
+
+	changes, err := priceDB.FindHistory(ctx, product.ID.String(), 0, 0)
+	assert.Nil(t, err)
+	assert.Len(t, changes, 4)
+	for _, change := range changes[:3] {
+		assert.NotNil(t, change.ScheduledPriceID)
+		assert.Equal(t, "user", change.ChangedBy)
+	}
+}
+
+func TestPrice_ApplyScheduledKeepsChangedPrice(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+	productDB := NewProduct(db)
+	priceDB := NewPrice(db)
+
+	product, err := entity.NewProduct("Shirt", "", usd(2000))
+	assert.Nil(t, err)
+	assert.Nil(t, productDB.Create(ctx, product))
+
+	start := time.Now().Add(time.Hour)
+	end := start.Add(24 * time.Hour)
+	schedulePrice(t, priceDB, product, usd(1500), start, &end)
+	_, err = priceDB.ApplyScheduled(ctx, start)
+	assert.Nil(t, err)
+
+	found, err := productDB.FindByID(ctx, product.ID.String())
+	assert.Nil(t, err)
+	found.Price = usd(1800)
+	assert.Nil(t, productDB.Update(ctx, found))
+
+	applied, err := priceDB.ApplyScheduled(ctx, end)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), applied)
+	found, err = productDB.FindByID(ctx, product.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, usd(1800), found.Price)
+}
+
+func TestPrice_ApplyScheduledMissedOrDeleted(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+	productDB := NewProduct(db)
+	priceDB := NewPrice(db)
+
+	missed, err := entity.NewProduct("Shirt", "", usd(2000))
+	assert.Nil(t, err)
+	assert.Nil(t, productDB.Create(ctx, missed))
+	deleted, err := entity.NewProduct("Hat", "", usd(1000))
+	assert.Nil(t, err)
+	assert.Nil(t, productDB.Create(ctx, deleted))
+
+	start := time.Now().Add(time.Hour)
+	end := start.Add(time.Hour)
+	promotion := schedulePrice(t, priceDB, missed, usd(1500), start, &end)
+	raise := schedulePrice(t, priceDB, deleted, usd(1200), start, nil)
+	assert.Nil(t, productDB.Delete(ctx, deleted.ID.String()))
+
+	applied, err := priceDB.ApplyScheduled(ctx, end)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), applied)
+
+	found, err := productDB.FindByID(ctx, missed.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, usd(2000), found.Price)
+	scheduled, err := priceDB.FindScheduledByID(ctx, missed.ID.String(), promotion.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, entity.ScheduledPriceFinished, scheduled.Status)
+	scheduled, err = priceDB.FindScheduledByID(ctx, deleted.ID.String(), raise.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, entity.ScheduledPriceCanceled, scheduled.Status)
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"gorm.io/gorm"
+)
+
+const createBatchSize = 100
+
+// BatchError reports the item that made a batch fail and roll back.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// CreateBatch creates every product and its tags in a single transaction,
+// recording their prices and auditing them like Create.
+func (p *Product) CreateBatch(ctx context.Context, products []*entity.Product) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(products, createBatchSize).Error; err != nil {
+			return err
+		}
+		for _, product := range products {
+			if err := recordPrice(tx, product, nil, product.OwnerID.String()); err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, tx, entity.AuditCreate, entity.AuditProduct, product.ID.String(), nil, product); err != nil {
+				return err
+			}
+		}
+		return insertTags(tx, products...)
+	})
+}
+
+// UpdateBatch updates every product in a single transaction, with the same
+// version check as Update. It returns a *BatchError for the first product that fails.
+func (p *Product) UpdateBatch(ctx context.Context, products []*entity.Product) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		productTx := NewProduct(tx)
+		for i, product := range products {
+			if err := productTx.Update(ctx, product); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteBatch deletes every product in a single transaction if its Version is
+// still the stored one. It returns a *BatchError for the first product that fails.
+func (p *Product) DeleteBatch(ctx context.Context, products []*entity.Product) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		productTx := NewProduct(tx)
+		for i, product := range products {
+			if err := productTx.DeleteVersion(ctx, product.ID.String(), product.Version); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func newBatch(t *testing.T, size int) []*entity.Product {
+	var products []*entity.Product
+	for i := 1; i <= size; i++ {
+		product, err := entity.NewProduct(fmt.Sprintf("Product %d", i), "", usd(1000))
+		assert.Nil(t, err)
+		products = append(products, product)
+	}
+	return products
+}
+
+func TestProduct_CreateBatch(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	productDB := NewProduct(db)
+	assert.Nil(t, productDB.CreateBatch(ctx, newBatch(t, 250)))
+
+	count, err := productDB.GetProductsCount(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 250, count)
+}
+
+func TestProduct_CreateBatchRollsBack(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	products := newBatch(t, 3)
+	products[2].ID = products[0].ID
+
+	productDB := NewProduct(db)
+	assert.Error(t, productDB.CreateBatch(ctx, products))
+
+	count, err := productDB.GetProductsCount(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestProduct_UpdateBatch(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	products := newBatch(t, 3)
+	productDB := NewProduct(db)
+	assert.Nil(t, productDB.CreateBatch(ctx, products))
+
+	for _, product := range products {
+		product.Price = usd(2000)
+	}
+	assert.Nil(t, productDB.UpdateBatch(ctx, products))
+
+	stale, _ := productDB.FindByID(ctx, products[1].ID.String())
+	stale.Version = 1
+	stale.Price = usd(3000)
+	products[0].Price = usd(3000)
+
+	err := productDB.UpdateBatch(ctx, []*entity.Product{products[0], stale})
+	var batchErr *BatchError
+	assert.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, 1, batchErr.Index)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+
+	first, err := productDB.FindByID(ctx, products[0].ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, usd(2000), first.Price)
+	assert.Equal(t, 2, first.Version)
+}
+
+func TestProduct_DeleteBatch(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	products := newBatch(t, 3)
+	productDB := NewProduct(db)
+	assert.Nil(t, productDB.CreateBatch(ctx, products))
+
+	missing := newBatch(t, 1)[0]
+	err := productDB.DeleteBatch(ctx, []*entity.Product{products[0], missing})
+	var batchErr *BatchError
+	assert.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, 1, batchErr.Index)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	count, _ := productDB.GetProductsCount(ctx)
+	assert.Equal(t, 3, count)
+
+	assert.Nil(t, productDB.DeleteBatch(ctx, products[:2]))
+	count, _ = productDB.GetProductsCount(ctx)
+	assert.Equal(t, 1, count)
+}
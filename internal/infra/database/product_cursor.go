@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"time"
+)
+
+var (
+	ErrInvalidCursor      = errors.New("invalid cursor")
+	ErrInvalidCursorSort  = errors.New("cursor pagination only sorts by created_at: use asc or desc")
+	ErrInvalidCursorLimit = errors.New("cursor pagination needs a limit of at least 1")
+)
+
+// ProductCursor is a keyset position in the products ordered by created_at and id.
+// Before selects the page preceding the position instead of the following one.
+type ProductCursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        string    `json:"i"`
+	Before    bool      `json:"b,omitempty"`
+}
+
+func CursorAfter(product entity.Product) *ProductCursor {
+	return &ProductCursor{CreatedAt: product.CreatedAt, ID: product.ID.String()}
+}
+
+func CursorBefore(product entity.Product) *ProductCursor {
+	return &ProductCursor{CreatedAt: product.CreatedAt, ID: product.ID.String(), Before: true}
+}
+
+// Encode returns the cursor as an opaque URL-safe string.
+func (c *ProductCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeProductCursor(s string) (*ProductCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor ProductCursor
+	if err = json.Unmarshal(raw, &cursor); err != nil || cursor.ID == "" || cursor.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// SearchByCursor returns up to limit products matching filter next to cursor,
+// or the first ones when cursor is nil, ordered by created_at and id. hasMore
+// reports whether more products exist past the returned page in the direction
+// of the cursor.
+func (p *Product) SearchByCursor(ctx context.Context, filter ProductFilter, cursor *ProductCursor, limit int, sort string) (products []entity.Product, hasMore bool, err error) {
+	if limit < 1 {
+		return nil, false, ErrInvalidCursorLimit
+	}
+
+	var desc bool
+	switch sort {
+	case "", "asc":
+	case "desc":
+		desc = true
+	default:
+		return nil, false, ErrInvalidCursorSort
+	}
+
+	backwards := cursor != nil && cursor.Before
+	// walking backwards reverses the order in the query, results are flipped back below
+	queryDesc := desc != backwards
+
+	query := p.filtered(ctx, filter)
+	if cursor != nil {
+		op := ">"
+		if queryDesc {
+			op = "<"
+		}
+		query = query.Where(
+			"(created_at "+op+" ? OR (created_at = ? AND id "+op+" ?))",
+			cursor.CreatedAt, cursor.CreatedAt, cursor.ID,
+		)
+	}
+
+	err = orderBy(query, []SortField{{Column: "created_at", Desc: queryDesc}, {Column: "id", Desc: queryDesc}}).
+		Limit(limit + 1).
+		Find(&products).Error
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(products) > limit {
+		hasMore = true
+		products = products[:limit]
+	}
+	if backwards {
+		for i, j := 0, len(products)-1; i < j; i, j = i+1, j-1 {
+			products[i], products[j] = products[j], products[i]
+		}
+	}
+
+	return products, hasMore, loadRelatedOf(p.DB.WithContext(ctx), products)
+}
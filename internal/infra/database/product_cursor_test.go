@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func names(products []entity.Product) []string {
+	var result []string
+	for _, product := range products {
+		result = append(result, product.Name)
+	}
+	return result
+}
+
+func TestProduct_SearchByCursor(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	createdAt := time.Now().Add(-time.Hour)
+	for i := 1; i <= 7; i++ {
+		product, err := entity.NewProduct(fmt.Sprintf("Product %d", i), "", usd(1000))
+		assert.Nil(t, err)
+		product.CreatedAt = createdAt.Add(time.Duration(i) * time.Minute)
+		db.Create(product)
+	}
+
+	productDB := NewProduct(db)
+
+	products, hasMore, err := productDB.SearchByCursor(ctx, ProductFilter{}, nil, 3, "asc")
+	assert.Nil(t, err)
+	assert.True(t, hasMore)
+	assert.Equal(t, []string{"Product 1", "Product 2", "Product 3"}, names(products))
+
+	products, hasMore, err = productDB.SearchByCursor(ctx, ProductFilter{}, CursorAfter(products[2]), 3, "asc")
+	assert.Nil(t, err)
+	assert.True(t, hasMore)
+	assert.Equal(t, []string{"Product 4", "Product 5", "Product 6"}, names(products))
+
+	previous, hasMore, err := productDB.SearchByCursor(ctx, ProductFilter{}, CursorBefore(products[0]), 3, "asc")
+	assert.Nil(t, err)
+	assert.False(t, hasMore)
+	assert.Equal(t, []string{"Product 1", "Product 2", "Product 3"}, names(previous))
+
+	products, hasMore, err = productDB.SearchByCursor(ctx, ProductFilter{}, CursorAfter(products[2]), 3, "asc")
+	assert.Nil(t, err)
+	assert.False(t, hasMore)
+	assert.Equal(t, []string{"Product 7"}, names(products))
+
+	products, hasMore, err = productDB.SearchByCursor(ctx, ProductFilter{}, nil, 4, "desc")
+	assert.Nil(t, err)
+	assert.True(t, hasMore)
+	assert.Equal(t, []string{"Product 7", "Product 6", "Product 5", "Product 4"}, names(products))
+
+	products, hasMore, err = productDB.SearchByCursor(ctx, ProductFilter{}, CursorAfter(products[3]), 4, "desc")
+	assert.Nil(t, err)
+	assert.False(t, hasMore)
+	assert.Equal(t, []string{"Product 3", "Product 2", "Product 1"}, names(products))
+
+	_, _, err = productDB.SearchByCursor(ctx, ProductFilter{}, nil, 4, "name")
+	assert.ErrorIs(t, err, ErrInvalidCursorSort)
+
+	for _, limit := range []int{0, -1, -2} {
+		_, _, err = productDB.SearchByCursor(ctx, ProductFilter{}, nil, limit, "asc")
+		assert.ErrorIs(t, err, ErrInvalidCursorLimit)
+	}
+}
+
+func TestProduct_SearchByCursorWithSameCreatedAt(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	createdAt := time.Now()
+	for i := 1; i <= 4; i++ {
+		product, err := entity.NewProduct(fmt.Sprintf("Product %d", i), "", usd(1000))
+		assert.Nil(t, err)
+		product.CreatedAt = createdAt
+		db.Create(product)
+	}
+
+	productDB := NewProduct(db)
+
+	first, _, err := productDB.SearchByCursor(ctx, ProductFilter{}, nil, 2, "asc")
+	assert.Nil(t, err)
+	second, hasMore, err := productDB.SearchByCursor(ctx, ProductFilter{}, CursorAfter(first[1]), 2, "asc")
+	assert.Nil(t, err)
+	assert.False(t, hasMore)
+	assert.Len(t, second, 2)
+
+	seen := map[string]bool{}
+	for _, product := range append(first, second...) {
+		seen[product.Name] = true
+	}
+	assert.Len(t, seen, 4)
+}
+
+func TestProductCursor_Encode(t *testing.T) {
+	product, err := entity.NewProduct("Product 1", "", usd(1000))
+	assert.Nil(t, err)
+
+	cursor, err := DecodeProductCursor(CursorBefore(*product).Encode())
+	assert.Nil(t, err)
+	assert.Equal(t, product.ID.String(), cursor.ID)
+	assert.True(t, product.CreatedAt.Equal(cursor.CreatedAt))
+	assert.True(t, cursor.Before)
+
+	for _, value := range []string{"not a cursor", "e30", "!!"} {
+		_, err = DecodeProductCursor(value)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	}
+}
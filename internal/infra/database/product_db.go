@@ -1,8 +1,13 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
 	"gorm.io/gorm"
+	"strings"
+	"time"
 )
 
 type Product struct {
@@ -13,52 +18,312 @@ func NewProduct(db *gorm.DB) *Product {
 	return &Product{DB: db}
 }
 
-func (p *Product) Create(product *entity.Product) error {
-	return p.DB.Create(product).Error
+var ErrVersionConflict = errors.New("product was changed by another request")
+
+// Create stores product along with its tags, records its price as the
+// first one of its history and audits its creation.
+func (p *Product) Create(ctx context.Context, product *entity.Product) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			return err
+		}
+		if err := recordPrice(tx, product, nil, product.OwnerID.String()); err != nil {
+			return err
+		}
+		if err := insertTags(tx, product); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, entity.AuditCreate, entity.AuditProduct, product.ID.String(), nil, product)
+	})
+}
+
+func (p *Product) FindAll(ctx context.Context, page, limit int, sort string) ([]entity.Product, error) {
+	return p.Search(ctx, ProductFilter{}, page, limit, sort)
 }
 
-func (p *Product) FindAll(page, limit int, sort string) ([]entity.Product, error) {
+// Search returns the products matching filter ordered by sort, as parsed by
+// ParseProductSort. An invalid sort returns ErrInvalidSortField.
+func (p *Product) Search(ctx context.Context, filter ProductFilter, page, limit int, sort string) ([]entity.Product, error) {
 	var products []entity.Product
 	var err error
 
-	if sort != "" && sort != "asc" && sort != "desc" {
-		sort = "asc"
+	sortFields, err := ParseProductSort(sort)
+	if err != nil {
+		return nil, err
 	}
-	if page != 0 && limit != 0 {
-		err = p.DB.Limit(limit).Offset((page - 1) * limit).Order("created_at " + sort).Find(&products).Error
+	query := orderBy(p.filtered(ctx, filter), sortFields)
+	if page > 0 && limit > 0 {
+		err = query.Limit(limit).Offset((page - 1) * limit).Find(&products).Error
 	} else {
-		err = p.DB.Order("created_at " + sort).Find(&products).Error
+		err = query.Find(&products).Error
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return products, err
+	return products, loadRelatedOf(p.DB.WithContext(ctx), products)
 }
 
-func (p *Product) GetProductsCount() (int, error) {
+// Each calls fn with every product matching filter ordered by sort, reading
+// them one at a time from the database instead of loading all of them.
+// It stops at the first error returned by fn. The images of the products aren't loaded.
+func (p *Product) Each(ctx context.Context, filter ProductFilter, sort string, fn func(product *entity.Product) error) error {
+	sortFields, err := ParseProductSort(sort)
+	if err != nil {
+		return err
+	}
+
+	// tags are joined instead of loaded apart, as the connection is busy with the rows,
+	// and the rows of a product come together since the order ends with its id
+	rows, err := orderBy(p.filtered(ctx, filter), sortFields).
+		Model(&entity.Product{}).
+		Select("products.*, product_tags.tag AS tag").
+		Joins("LEFT JOIN product_tags ON product_tags.product_id = products.id").
+		Order("product_tags.tag").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var product *entity.Product
+	for rows.Next() {
+		var row productTagRow
+		if err = p.DB.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if product == nil || product.ID != row.ID {
+			if product != nil {
+				if err = fn(product); err != nil {
+					return err
+				}
+			}
+			product = &row.Product
+			product.Tags = []string{}
+		}
+		if row.Tag != nil {
+			product.Tags = append(product.Tags, *row.Tag)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	if product != nil {
+		return fn(product)
+	}
+	return nil
+}
+
+// productTagRow is a product joined with one of its tags, if it has any.
+type productTagRow struct {
+	entity.Product
+	Tag *string
+}
+
+func (p *Product) GetProductsCount(ctx context.Context) (int, error) {
+	return p.Count(ctx, ProductFilter{})
+}
+
+func (p *Product) Count(ctx context.Context, filter ProductFilter) (int, error) {
 	var count int64
-	err := p.DB.Model(&entity.Product{}).Count(&count).Error
+	err := p.filtered(ctx, filter).Model(&entity.Product{}).Count(&count).Error
 	return int(count), err
 }
 
-func (p *Product) FindByID(id string) (*entity.Product, error) {
+func (p *Product) filtered(ctx context.Context, filter ProductFilter) *gorm.DB {
+	query := p.DB.WithContext(ctx)
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
+	if filter.OwnerID != "" {
+		query = query.Where("owner_id = ?", filter.OwnerID)
+	}
+	if filter.Text != "" {
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(filter.Text)) + "%"
+		query = query.Where("(LOWER(name) LIKE ? ESCAPE '!' OR LOWER(description) LIKE ? ESCAPE '!')", pattern, pattern)
+	}
+	if len(filter.MinPrices) > 0 {
+		condition, args := priceBounds(filter.MinPrices, ">=")
+		query = query.Where(condition, args...)
+	}
+	if len(filter.MaxPrices) > 0 {
+		condition, args := priceBounds(filter.MaxPrices, "<=")
+		query = query.Where(condition, args...)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at <= ?", *filter.CreatedTo)
+	}
+	if len(filter.Tags) > 0 {
+		if filter.AllTags {
+			query = query.Where("id IN (SELECT product_id FROM product_tags WHERE tag IN ? GROUP BY product_id HAVING COUNT(*) = ?)", filter.Tags, len(filter.Tags))
+		} else {
+			query = query.Where("id IN (SELECT product_id FROM product_tags WHERE tag IN ?)", filter.Tags)
+		}
+	}
+	if filter.CategoryID != "" {
+		query = query.Where("id IN (SELECT product_id FROM product_categories WHERE category_id IN ("+categoryTreeSQL+"))", filter.CategoryID)
+	}
+	return query
+}
+
+// priceBounds returns a condition comparing the price of a product with
+// operator to the bound in its currency.
+func priceBounds(bounds []entity2.Money, operator string) (string, []interface{}) {
+	conditions := make([]string, len(bounds))
+	args := make([]interface{}, 0, 2*len(bounds))
+	for i, bound := range bounds {
+		conditions[i] = "(price_currency = ? AND price_amount " + operator + " ?)"
+		args = append(args, bound.Currency, bound.Amount)
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+func (p *Product) FindByID(ctx context.Context, id string) (*entity.Product, error) {
 	var product entity.Product
-	err := p.DB.Where("id = ?", id).First(&product).Error
+	err := p.DB.WithContext(ctx).Where("id = ?", id).First(&product).Error
+	if err == nil {
+		err = loadRelated(p.DB.WithContext(ctx), &product)
+	}
 	return &product, err
 }
 
-func (p *Product) Update(product *entity.Product) error {
-	_, err := p.FindByID(product.ID.String())
+// FindByIDWithDeleted finds the product even when it is soft deleted.
+func (p *Product) FindByIDWithDeleted(ctx context.Context, id string) (*entity.Product, error) {
+	var product entity.Product
+	err := p.DB.WithContext(ctx).Unscoped().Where("id = ?", id).First(&product).Error
+	if err == nil {
+		err = loadRelated(p.DB.WithContext(ctx), &product)
+	}
+	return &product, err
+}
+
+// Update saves product and its tags if its Version is still the stored one
+// and increments it, recording a new price in the price history and auditing
+// the change on behalf of the actor of ctx. It returns ErrVersionConflict
+// when the product was changed meanwhile.
+func (p *Product) Update(ctx context.Context, product *entity.Product) error {
+	expected := product.Version
+	product.Version++
+
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stored, err := NewProduct(tx).FindByID(ctx, product.ID.String())
+		if err != nil {
+			return err
+		}
+		if stored.Version != expected {
+			return ErrVersionConflict
+		}
+
+		result := tx.Model(product).
+			Where("version = ?", expected).
+			Select("*").
+			Omit("ID", "CreatedAt", "DeletedAt").
+			Updates(product)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return NewProduct(tx).conflictOrNotFound(ctx, product.ID.String())
+		}
+		if err := recordPrice(tx, product, &stored.Price, actorFrom(ctx)); err != nil {
+			return err
+		}
+		if err := replaceTags(tx, product); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, entity.AuditUpdate, entity.AuditProduct, product.ID.String(), stored, product)
+	})
 	if err != nil {
-		return err
+		product.Version = expected
 	}
 
-	return p.DB.Save(product).Error
+	return err
 }
 
-func (p *Product) Delete(id string) error {
-	product, err := p.FindByID(id)
+// Delete deletes the product whatever its version and audits it.
+func (p *Product) Delete(ctx context.Context, id string) error {
+	product, err := p.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	return p.DB.Delete(product).Error
+	return p.DeleteVersion(ctx, id, product.Version)
+}
+
+// DeleteVersion deletes the product only if version is still the stored one,
+// and audits it. It returns ErrVersionConflict when the product was changed meanwhile.
+func (p *Product) DeleteVersion(ctx context.Context, id string, version int) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stored, err := NewProduct(tx).FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		result := tx.Where("id = ? AND version = ?", id, version).Delete(&entity.Product{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return NewProduct(tx).conflictOrNotFound(ctx, id)
+		}
+		return recordAudit(ctx, tx, entity.AuditDelete, entity.AuditProduct, id, stored, nil)
+	})
+}
+
+func (p *Product) conflictOrNotFound(ctx context.Context, id string) error {
+	if _, err := p.FindByID(ctx, id); err != nil {
+		return err
+	}
+	return ErrVersionConflict
+}
+
+// Restore clears the tombstone of a soft deleted product and audits it. It
+// returns gorm.ErrRecordNotFound when no deleted product has id.
+func (p *Product) Restore(ctx context.Context, id string) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stored, err := NewProduct(tx).FindByIDWithDeleted(ctx, id)
+		if err != nil {
+			return err
+		}
+		result := tx.Unscoped().Model(&entity.Product{}).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		restored := *stored
+		restored.DeletedAt = gorm.DeletedAt{}
+		return recordAudit(ctx, tx, entity.AuditRestore, entity.AuditProduct, id, stored, &restored)
+	})
+}
+
+// PurgeDeleted permanently removes the products soft deleted before before,
+// along with their category links, tags, stock, reservations, variants and prices. Their images are kept
+// until their blobs are removed, see Image.FindOrphaned.
+func (p *Product) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		deleted := tx.Unscoped().Model(&entity.Product{}).Select("id").Where("deleted_at < ?", before)
+		for _, related := range []interface{}{&productCategory{}, &productTag{}, &entity.Inventory{}, &entity.StockAdjustment{}, &entity.Variant{}, &entity.PriceChange{}, &entity.ScheduledPrice{}, &entity.StockReservationItem{}} {
+			if err := tx.Where("product_id IN (?)", deleted).Delete(related).Error; err != nil {
+				return err
+			}
+		}
+		// reservations of other products too keep their items of those products
+		reserved := tx.Model(&entity.StockReservationItem{}).Select("reservation_id")
+		if err := tx.Where("id NOT IN (?)", reserved).Delete(&entity.StockReservation{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("deleted_at < ?", before).Delete(&entity.Product{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
 }
@@ -1,23 +1,31 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
 	"github.com/SchunckLeonardo/go-expert-api/test/utils"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 	"log"
 	"math/rand"
 	"testing"
+	"time"
 )
 
+func usd(amount int64) entity2.Money {
+	return entity2.Money{Amount: amount, Currency: entity2.DefaultCurrency}
+}
+
 func TestProduct_Create(t *testing.T) {
+	ctx := context.Background()
 	db := utils.OpenDBConnection(t)
 
-	product, _ := entity.NewProduct("Product 1", "Description 1", 80.0)
+	product, _ := entity.NewProduct("Product 1", "Description 1", usd(8000))
 	productDB := NewProduct(db)
 
-	err := productDB.Create(product)
+	err := productDB.Create(ctx, product)
 	assert.Nil(t, err)
 	assert.NotEmpty(t, product.ID)
 
@@ -30,27 +38,28 @@ func TestProduct_Create(t *testing.T) {
 }
 
 func TestProduct_FindAll(t *testing.T) {
+	ctx := context.Background()
 	db := utils.OpenDBConnection(t)
 
 	for i := 1; i < 24; i++ {
-		product, err := entity.NewProduct(fmt.Sprintf("Product %d", i), fmt.Sprintf("Description %d", i), rand.Float64())
+		product, err := entity.NewProduct(fmt.Sprintf("Product %d", i), fmt.Sprintf("Description %d", i), usd(rand.Int63n(100000)+1))
 		assert.NoError(t, err)
 		db.Create(product)
 	}
 
 	productDB := NewProduct(db)
-	products, err := productDB.FindAll(1, 10, "asc")
+	products, err := productDB.FindAll(ctx, 1, 10, "asc")
 	assert.NoError(t, err)
 	assert.Equal(t, "Product 1", products[0].Name)
 	assert.Equal(t, "Product 10", products[9].Name)
 
-	products, err = productDB.FindAll(2, 10, "asc")
+	products, err = productDB.FindAll(ctx, 2, 10, "asc")
 	assert.NoError(t, err)
 	assert.Len(t, products, 10)
 	assert.Equal(t, "Product 11", products[0].Name)
 	assert.Equal(t, "Product 20", products[9].Name)
 
-	products, err = productDB.FindAll(3, 10, "asc")
+	products, err = productDB.FindAll(ctx, 3, 10, "asc")
 	assert.NoError(t, err)
 	assert.Len(t, products, 3)
 	assert.Equal(t, "Product 21", products[0].Name)
@@ -58,16 +67,17 @@ func TestProduct_FindAll(t *testing.T) {
 }
 
 func TestProduct_FindByID(t *testing.T) {
+	ctx := context.Background()
 	db := utils.OpenDBConnection(t)
 
-	product, err := entity.NewProduct("Laptop", "Macbook M1", 1100.00)
+	product, err := entity.NewProduct("Laptop", "Macbook M1", usd(110000))
 	assert.Nil(t, err)
 
 	db.Create(&product)
 
 	productDB := NewProduct(db)
 
-	productFounded, err := productDB.FindByID(product.ID.String())
+	productFounded, err := productDB.FindByID(ctx, product.ID.String())
 	assert.Nil(t, err)
 	assert.NotNil(t, productFounded)
 
@@ -78,9 +88,10 @@ func TestProduct_FindByID(t *testing.T) {
 }
 
 func TestProduct_Update(t *testing.T) {
+	ctx := context.Background()
 	db := utils.OpenDBConnection(t)
 
-	product, err := entity.NewProduct("Laptop", "Macbook M1", 1100.00)
+	product, err := entity.NewProduct("Laptop", "Macbook M1", usd(110000))
 	assert.Nil(t, err)
 
 	db.Create(&product)
@@ -89,7 +100,7 @@ func TestProduct_Update(t *testing.T) {
 
 	product.Name = "Laptop 2"
 
-	err = productDB.Update(product)
+	err = productDB.Update(ctx, product)
 	assert.Nil(t, err)
 
 	var productUpdated entity.Product
@@ -100,16 +111,17 @@ func TestProduct_Update(t *testing.T) {
 }
 
 func TestProduct_Delete(t *testing.T) {
+	ctx := context.Background()
 	db := utils.OpenDBConnection(t)
 
-	product, err := entity.NewProduct("Laptop", "Macbook M1", 1100.00)
+	product, err := entity.NewProduct("Laptop", "Macbook M1", usd(110000))
 	assert.Nil(t, err)
 
 	db.Create(&product)
 
 	productDB := NewProduct(db)
 
-	err = productDB.Delete(product.ID.String())
+	err = productDB.Delete(ctx, product.ID.String())
 	assert.Nil(t, err)
 
 	var productUpdated entity.Product
@@ -118,12 +130,13 @@ func TestProduct_Delete(t *testing.T) {
 }
 
 func TestProduct_GetProductsCount(t *testing.T) {
+	ctx := context.Background()
 	db := utils.OpenDBConnection(t)
 
 	var products []entity.Product
 
 	for i := 1; i < 5; i++ {
-		product, err := entity.NewProduct(fmt.Sprintf("Laptop %d", i), "Macbook M1", 1100.00)
+		product, err := entity.NewProduct(fmt.Sprintf("Laptop %d", i), "Macbook M1", usd(110000))
 		assert.Nil(t, err)
 
 		products = append(products, *product)
@@ -133,9 +146,341 @@ func TestProduct_GetProductsCount(t *testing.T) {
 
 	productDb := NewProduct(db)
 
-	count, err := productDb.GetProductsCount()
+	count, err := productDb.GetProductsCount(ctx)
 	assert.Nil(t, err)
 	assert.NotNil(t, count)
 
 	assert.Equal(t, len(products), count)
 }
+
+func TestProduct_SearchByOwner(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	ownerID := entity2.NewID()
+	for i := 1; i < 6; i++ {
+		product, err := entity.NewProduct(fmt.Sprintf("Product %d", i), "", usd(1000))
+		assert.Nil(t, err)
+		if i%2 == 0 {
+			product.OwnerID = ownerID
+		}
+		db.Create(product)
+	}
+
+	productDB := NewProduct(db)
+
+	products, err := productDB.Search(ctx, ProductFilter{OwnerID: ownerID.String()}, 1, 10, "asc")
+	assert.Nil(t, err)
+	assert.Len(t, products, 2)
+	assert.Equal(t, "Product 2", products[0].Name)
+	assert.Equal(t, ownerID, products[0].OwnerID)
+
+	count, err := productDB.Count(ctx, ProductFilter{OwnerID: ownerID.String()})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	count, err = productDB.Count(ctx, ProductFilter{})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, count)
+}
+
+func TestProduct_SearchByText(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	for _, p := range [][2]string{
+		{"Laptop", "Macbook M1"},
+		{"Phone", "iPhone 16 with MacSafe"},
+		{"Monitor", "100% sRGB"},
+		{"Keyboard", "mechanical"},
+	} {
+		product, err := entity.NewProduct(p[0], p[1], usd(1000))
+		assert.Nil(t, err)
+		db.Create(product)
+	}
+
+	productDB := NewProduct(db)
+
+	products, err := productDB.Search(ctx, ProductFilter{Text: "mac"}, 1, 10, "asc")
+	assert.Nil(t, err)
+	assert.Len(t, products, 2)
+	assert.Equal(t, "Laptop", products[0].Name)
+	assert.Equal(t, "Phone", products[1].Name)
+
+	products, err = productDB.Search(ctx, ProductFilter{Text: "KEYBOARD"}, 1, 10, "asc")
+	assert.Nil(t, err)
+	assert.Len(t, products, 1)
+
+	products, err = productDB.Search(ctx, ProductFilter{Text: "100%"}, 1, 10, "asc")
+	assert.Nil(t, err)
+	assert.Len(t, products, 1)
+	assert.Equal(t, "Monitor", products[0].Name)
+
+	products, err = productDB.Search(ctx, ProductFilter{Text: "%"}, 1, 10, "asc")
+	assert.Nil(t, err)
+	assert.Len(t, products, 1)
+}
+
+func TestProduct_SearchByPriceAndDate(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	now := time.Now()
+	for i := 1; i <= 5; i++ {
+		product, err := entity.NewProduct(fmt.Sprintf("Product %d", i), "", usd(int64(i*1000)))
+		assert.Nil(t, err)
+		product.CreatedAt = now.AddDate(0, 0, -i)
+		db.Create(product)
+	}
+
+	productDB := NewProduct(db)
+
+	minPrices, maxPrices := []entity2.Money{usd(2000)}, []entity2.Money{usd(4000)}
+	products, err := productDB.Search(ctx, ProductFilter{MinPrices: minPrices, MaxPrices: maxPrices}, 1, 10, "desc")
+	assert.Nil(t, err)
+	assert.Len(t, products, 3)
+	assert.Equal(t, "Product 2", products[0].Name)
+	assert.Equal(t, "Product 4", products[2].Name)
+
+	euros := []entity2.Money{{Amount: 2000, Currency: "EUR"}}
+	products, err = productDB.Search(ctx, ProductFilter{MinPrices: euros}, 1, 10, "desc")
+	assert.Nil(t, err)
+	assert.Empty(t, products)
+
+	yen, err := entity.NewProduct("Yen", "", entity2.Money{Amount: 3000, Currency: "JPY"})
+	assert.Nil(t, err)
+	db.Create(yen)
+	products, err = productDB.Search(ctx, ProductFilter{
+		MinPrices: []entity2.Money{usd(5000), {Amount: 2000, Currency: "JPY"}},
+		MaxPrices: []entity2.Money{usd(6000), {Amount: 4000, Currency: "JPY"}},
+	}, 1, 10, "desc")
+	assert.Nil(t, err)
+	assert.Len(t, products, 2)
+
+	createdFrom := now.AddDate(0, 0, -3).Add(-time.Minute)
+	createdTo := now.AddDate(0, 0, -2).Add(time.Minute)
+	products, err = productDB.Search(ctx, ProductFilter{CreatedFrom: &createdFrom, CreatedTo: &createdTo}, 1, 10, "asc")
+	assert.Nil(t, err)
+	assert.Len(t, products, 2)
+	assert.Equal(t, "Product 3", products[0].Name)
+
+	count, err := productDB.Count(ctx, ProductFilter{MinPrices: minPrices, CreatedTo: &createdTo})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, count)
+}
+
+func TestProduct_SearchSortedByFields(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	for _, p := range []struct {
+		name  string
+		price int64
+	}{
+		{"B", 2000}, {"A", 3000}, {"B", 1000}, {"C", 1000},
+	} {
+		product, err := entity.NewProduct(p.name, "", usd(p.price))
+		assert.Nil(t, err)
+		db.Create(product)
+	}
+
+	productDB := NewProduct(db)
+
+	products, err := productDB.Search(ctx, ProductFilter{}, 1, 10, "name,-price")
+	assert.Nil(t, err)
+	assert.Len(t, products, 4)
+	assert.Equal(t, "A", products[0].Name)
+	assert.Equal(t, "B", products[1].Name)
+	assert.Equal(t, usd(2000), products[1].Price)
+	assert.Equal(t, "B", products[2].Name)
+	assert.Equal(t, usd(1000), products[2].Price)
+	assert.Equal(t, "C", products[3].Name)
+
+	products, err = productDB.FindAll(ctx, 1, 10, "price,-name")
+	assert.Nil(t, err)
+	assert.Equal(t, "C", products[0].Name)
+	assert.Equal(t, "B", products[1].Name)
+	assert.Equal(t, "A", products[3].Name)
+
+	_, err = productDB.FindAll(ctx, 1, 10, "password")
+	assert.ErrorIs(t, err, ErrInvalidSortField)
+}
+
+func TestProduct_SearchSortedByPriceWithinCurrency(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	for _, price := range []entity2.Money{usd(999), {Amount: 1000, Currency: "JPY"}, usd(500), {Amount: 200, Currency: "JPY"}} {
+		product, err := entity.NewProduct("Product", "", price)
+		assert.Nil(t, err)
+		db.Create(product)
+	}
+
+	products, err := NewProduct(db).Search(ctx, ProductFilter{}, 1, 10, "-price")
+	assert.Nil(t, err)
+	var prices []entity2.Money
+	for _, product := range products {
+		prices = append(prices, product.Price)
+	}
+	assert.Equal(t, []entity2.Money{{Amount: 1000, Currency: "JPY"}, {Amount: 200, Currency: "JPY"}, usd(999), usd(500)}, prices)
+}
+
+func TestProduct_SoftDeleteAndRestore(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	product, err := entity.NewProduct("Laptop", "Macbook M1", usd(110000))
+	assert.Nil(t, err)
+	db.Create(product)
+
+	productDB := NewProduct(db)
+	assert.Nil(t, productDB.Delete(ctx, product.ID.String()))
+
+	_, err = productDB.FindByID(ctx, product.ID.String())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	deleted, err := productDB.FindByIDWithDeleted(ctx, product.ID.String())
+	assert.Nil(t, err)
+	assert.True(t, deleted.IsDeleted())
+
+	count, err := productDB.Count(ctx, ProductFilter{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+
+	products, err := productDB.Search(ctx, ProductFilter{IncludeDeleted: true}, 1, 10, "")
+	assert.Nil(t, err)
+	assert.Len(t, products, 1)
+
+	assert.Nil(t, productDB.Restore(ctx, product.ID.String()))
+	assert.ErrorIs(t, productDB.Restore(ctx, product.ID.String()), gorm.ErrRecordNotFound)
+
+	restored, err := productDB.FindByID(ctx, product.ID.String())
+	assert.Nil(t, err)
+	assert.False(t, restored.IsDeleted())
+}
+
+func TestProduct_PurgeDeleted(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	productDB := NewProduct(db)
+	var ids []string
+	for i := 1; i <= 3; i++ {
+		product, err := entity.NewProduct(fmt.Sprintf("Product %d", i), "", usd(1000))
+		assert.Nil(t, err)
+		db.Create(product)
+		ids = append(ids, product.ID.String())
+	}
+
+	purgedID, _ := entity2.ParseID(ids[0])
+	keptID, _ := entity2.ParseID(ids[2])
+	onlyPurged, err := entity.NewStockReservation([]entity.StockReservationItem{{ProductID: purgedID, Quantity: 1}}, time.Hour)
+	assert.Nil(t, err)
+	assert.Nil(t, db.Create(onlyPurged).Error)
+	mixed, err := entity.NewStockReservation([]entity.StockReservationItem{{ProductID: purgedID, Quantity: 1}, {ProductID: keptID, Quantity: 2}}, time.Hour)
+	assert.Nil(t, err)
+	assert.Nil(t, db.Create(mixed).Error)
+
+	assert.Nil(t, productDB.Delete(ctx, ids[0]))
+	assert.Nil(t, productDB.Delete(ctx, ids[1]))
+	db.Unscoped().Model(&entity.Product{}).Where("id = ?", ids[0]).Update("deleted_at", time.Now().AddDate(0, 0, -40))
+
+	purged, err := productDB.PurgeDeleted(ctx, time.Now().AddDate(0, 0, -30))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	_, err = productDB.FindByIDWithDeleted(ctx, ids[0])
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	_, err = productDB.FindByIDWithDeleted(ctx, ids[1])
+	assert.Nil(t, err)
+
+	_, err = productDB.FindByID(ctx, ids[2])
+	assert.Nil(t, err)
+
+	var reservations []entity.StockReservation
+	assert.Nil(t, db.Preload("Items").Find(&reservations).Error)
+	assert.Len(t, reservations, 1)
+	assert.Equal(t, mixed.ID, reservations[0].ID)
+	assert.Equal(t, []entity.StockReservationItem{{ReservationID: mixed.ID, ProductID: keptID, Quantity: 2}}, reservations[0].Items)
+}
+
+func TestProduct_UpdateVersionConflict(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	product, err := entity.NewProduct("Laptop", "Macbook M1", usd(110000))
+	assert.Nil(t, err)
+	db.Create(product)
+
+	productDB := NewProduct(db)
+
+	first, _ := productDB.FindByID(ctx, product.ID.String())
+	second, _ := productDB.FindByID(ctx, product.ID.String())
+
+	first.Name = "Laptop 2"
+	assert.Nil(t, productDB.Update(ctx, first))
+	assert.Equal(t, 2, first.Version)
+
+	second.Name = "Laptop 3"
+	assert.ErrorIs(t, productDB.Update(ctx, second), ErrVersionConflict)
+	assert.Equal(t, 1, second.Version)
+
+	stored, err := productDB.FindByID(ctx, product.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, "Laptop 2", stored.Name)
+	assert.Equal(t, 2, stored.Version)
+
+	missing, _ := entity.NewProduct("Missing", "", usd(1000))
+	assert.ErrorIs(t, productDB.Update(ctx, missing), gorm.ErrRecordNotFound)
+}
+
+func TestProduct_DeleteVersion(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	product, err := entity.NewProduct("Laptop", "Macbook M1", usd(110000))
+	assert.Nil(t, err)
+	db.Create(product)
+
+	productDB := NewProduct(db)
+
+	product.Name = "Laptop 2"
+	assert.Nil(t, productDB.Update(ctx, product))
+
+	assert.ErrorIs(t, productDB.DeleteVersion(ctx, product.ID.String(), 1), ErrVersionConflict)
+	assert.Nil(t, productDB.DeleteVersion(ctx, product.ID.String(), 2))
+	assert.ErrorIs(t, productDB.DeleteVersion(ctx, product.ID.String(), 2), gorm.ErrRecordNotFound)
+}
+
+func TestProduct_Each(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	productDB := NewProduct(db)
+	for i := 1; i <= 5; i++ {
+		product, err := entity.NewProduct(fmt.Sprintf("Product %d", i), "", usd(int64(i*1000)))
+		assert.Nil(t, err)
+		assert.Nil(t, productDB.Create(ctx, product))
+	}
+
+	var names []string
+	err := productDB.Each(ctx, ProductFilter{MinPrices: []entity2.Money{usd(2000)}}, "-price", func(product *entity.Product) error {
+		names = append(names, product.Name)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Product 5", "Product 4", "Product 3", "Product 2"}, names)
+
+	stop := fmt.Errorf("stop")
+	calls := 0
+	err = productDB.Each(ctx, ProductFilter{}, "", func(product *entity.Product) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+
+	err = productDB.Each(ctx, ProductFilter{}, "unknown", func(product *entity.Product) error { return nil })
+	assert.ErrorIs(t, err, ErrInvalidSortField)
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+)
+
+var ErrInvalidSortField = errors.New("invalid sort field")
+
+// ProductSortFields maps the fields accepted in a sort expression to their columns.
+var ProductSortFields = map[string]string{
+	"id":          "id",
+	"name":        "name",
+	"description": "description",
+	"price":       "price_amount",
+	"created_at":  "created_at",
+}
+
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// ParseProductSort parses a comma separated list of fields, each one descending
+// when prefixed by "-", e.g. "name,-price". The legacy "asc" and "desc" values
+// sort by created_at and an empty expression sorts by created_at ascending.
+// Amounts only compare within a currency, so price sorts by currency first.
+func ParseProductSort(sort string) ([]SortField, error) {
+	switch strings.TrimSpace(sort) {
+	case "", "asc":
+		return []SortField{{Column: "created_at"}}, nil
+	case "desc":
+		return []SortField{{Column: "created_at", Desc: true}}, nil
+	}
+
+	var fields []SortField
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(sort, ",") {
+		part = strings.TrimSpace(part)
+		desc := strings.HasPrefix(part, "-")
+		name := strings.TrimPrefix(part, "-")
+
+		column, ok := ProductSortFields[name]
+		if !ok || seen[column] {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSortField, part)
+		}
+		seen[column] = true
+		if column == "price_amount" {
+			fields = append(fields, SortField{Column: "price_currency"})
+		}
+		fields = append(fields, SortField{Column: column, Desc: desc})
+	}
+
+	return fields, nil
+}
+
+// orderBy applies fields to query, ending with id so pages have a stable order.
+func orderBy(query *gorm.DB, fields []SortField) *gorm.DB {
+	hasID := false
+	for _, field := range fields {
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: field.Column}, Desc: field.Desc})
+		hasID = hasID || field.Column == "id"
+	}
+	if !hasID {
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}})
+	}
+	return query
+}
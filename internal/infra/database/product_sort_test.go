@@ -0,0 +1,25 @@
+package database
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseProductSort(t *testing.T) {
+	fields, err := ParseProductSort("")
+	assert.Nil(t, err)
+	assert.Equal(t, []SortField{{Column: "created_at"}}, fields)
+
+	fields, err = ParseProductSort("desc")
+	assert.Nil(t, err)
+	assert.Equal(t, []SortField{{Column: "created_at", Desc: true}}, fields)
+
+	fields, err = ParseProductSort("name, -price")
+	assert.Nil(t, err)
+	assert.Equal(t, []SortField{{Column: "name"}, {Column: "price_currency"}, {Column: "price_amount", Desc: true}}, fields)
+
+	for _, sort := range []string{"unknown", "name,", "name,-name", "--price", "price desc; DROP TABLE products"} {
+		_, err = ParseProductSort(sort)
+		assert.ErrorIs(t, err, ErrInvalidSortField, sort)
+	}
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"gorm.io/gorm"
+	"time"
+)
+
+type RefreshToken struct {
+	DB *gorm.DB
+}
+
+func NewRefreshToken(db *gorm.DB) *RefreshToken {
+	return &RefreshToken{DB: db}
+}
+
+func (r *RefreshToken) Create(ctx context.Context, token *entity.RefreshToken) error {
+	return r.DB.WithContext(ctx).Create(token).Error
+}
+
+func (r *RefreshToken) FindByHash(ctx context.Context, hash string) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+	if err := r.DB.WithContext(ctx).Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Rotate revokes current and stores next in a single transaction. It returns
+// entity.ErrRefreshTokenRevoked when current was already revoked, which means
+// the token was used twice.
+func (r *RefreshToken) Rotate(ctx context.Context, current, next *entity.RefreshToken) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", current.ID).
+			Update("revoked_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrRefreshTokenRevoked
+		}
+
+		return tx.Create(next).Error
+	})
+}
+
+func (r *RefreshToken) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.DB.WithContext(ctx).Model(&entity.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRefreshToken_FindByHash(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	refreshToken, token, err := entity.NewRefreshToken(entity2.NewID(), entity2.NewID(), time.Hour)
+	assert.Nil(t, err)
+
+	refreshTokenDB := NewRefreshToken(db)
+	assert.Nil(t, refreshTokenDB.Create(ctx, refreshToken))
+
+	found, err := refreshTokenDB.FindByHash(ctx, entity.HashRefreshToken(token))
+	assert.Nil(t, err)
+	assert.Equal(t, refreshToken.ID, found.ID)
+	assert.Equal(t, refreshToken.UserID, found.UserID)
+	assert.False(t, found.IsRevoked())
+
+	_, err = refreshTokenDB.FindByHash(ctx, entity.HashRefreshToken("unknown"))
+	assert.Error(t, err)
+}
+
+func TestRefreshToken_Rotate(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	userID := entity2.NewID()
+	familyID := entity2.NewID()
+	current, currentToken, _ := entity.NewRefreshToken(userID, familyID, time.Hour)
+	next, nextToken, _ := entity.NewRefreshToken(userID, familyID, time.Hour)
+
+	refreshTokenDB := NewRefreshToken(db)
+	assert.Nil(t, refreshTokenDB.Create(ctx, current))
+
+	err := refreshTokenDB.Rotate(ctx, current, next)
+	assert.Nil(t, err)
+
+	rotated, err := refreshTokenDB.FindByHash(ctx, entity.HashRefreshToken(currentToken))
+	assert.Nil(t, err)
+	assert.True(t, rotated.IsRevoked())
+
+	created, err := refreshTokenDB.FindByHash(ctx, entity.HashRefreshToken(nextToken))
+	assert.Nil(t, err)
+	assert.False(t, created.IsRevoked())
+
+	reused, _, _ := entity.NewRefreshToken(userID, familyID, time.Hour)
+	err = refreshTokenDB.Rotate(ctx, current, reused)
+	assert.ErrorIs(t, err, entity.ErrRefreshTokenRevoked)
+
+	var count int64
+	db.Model(&entity.RefreshToken{}).Where("id = ?", reused.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestRefreshToken_RevokeFamily(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	userID := entity2.NewID()
+	familyID := entity2.NewID()
+	first, firstToken, _ := entity.NewRefreshToken(userID, familyID, time.Hour)
+	second, secondToken, _ := entity.NewRefreshToken(userID, familyID, time.Hour)
+	other, otherToken, _ := entity.NewRefreshToken(userID, entity2.NewID(), time.Hour)
+
+	refreshTokenDB := NewRefreshToken(db)
+	assert.Nil(t, refreshTokenDB.Create(ctx, first))
+	assert.Nil(t, refreshTokenDB.Create(ctx, second))
+	assert.Nil(t, refreshTokenDB.Create(ctx, other))
+
+	assert.Nil(t, refreshTokenDB.RevokeFamily(ctx, familyID.String()))
+
+	for _, token := range []string{firstToken, secondToken} {
+		found, err := refreshTokenDB.FindByHash(ctx, entity.HashRefreshToken(token))
+		assert.Nil(t, err)
+		assert.True(t, found.IsRevoked())
+	}
+
+	found, err := refreshTokenDB.FindByHash(ctx, entity.HashRefreshToken(otherToken))
+	assert.Nil(t, err)
+	assert.False(t, found.IsRevoked())
+}
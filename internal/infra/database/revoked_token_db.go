@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type RevokedToken struct {
+	DB *gorm.DB
+}
+
+func NewRevokedToken(db *gorm.DB) *RevokedToken {
+	return &RevokedToken{DB: db}
+}
+
+// Create stores token, ignoring tokens that were already revoked.
+func (r *RevokedToken) Create(ctx context.Context, token *entity.RevokedToken) error {
+	return r.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+func (r *RevokedToken) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	err := r.DB.WithContext(ctx).Model(&entity.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+// DeleteExpired removes the tokens that expired before now, since they are
+// already rejected by their exp claim.
+func (r *RevokedToken) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.DB.WithContext(ctx).Where("expires_at < ?", now).Delete(&entity.RevokedToken{})
+	return result.RowsAffected, result.Error
+}
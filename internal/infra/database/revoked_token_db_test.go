@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRevokedToken_IsRevoked(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	revokedTokenDB := NewRevokedToken(db)
+
+	revoked, err := revokedTokenDB.IsRevoked(ctx, "jti-1")
+	assert.Nil(t, err)
+	assert.False(t, revoked)
+
+	assert.Nil(t, revokedTokenDB.Create(ctx, entity.NewRevokedToken("jti-1", time.Now().Add(time.Hour))))
+	assert.Nil(t, revokedTokenDB.Create(ctx, entity.NewRevokedToken("jti-1", time.Now().Add(time.Hour))))
+
+	revoked, err = revokedTokenDB.IsRevoked(ctx, "jti-1")
+	assert.Nil(t, err)
+	assert.True(t, revoked)
+}
+
+func TestRevokedToken_DeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	revokedTokenDB := NewRevokedToken(db)
+	assert.Nil(t, revokedTokenDB.Create(ctx, entity.NewRevokedToken("expired", time.Now().Add(-time.Minute))))
+	assert.Nil(t, revokedTokenDB.Create(ctx, entity.NewRevokedToken("active", time.Now().Add(time.Hour))))
+
+	deleted, err := revokedTokenDB.DeleteExpired(ctx, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	revoked, err := revokedTokenDB.IsRevoked(ctx, "expired")
+	assert.Nil(t, err)
+	assert.False(t, revoked)
+
+	revoked, err = revokedTokenDB.IsRevoked(ctx, "active")
+	assert.Nil(t, err)
+	assert.True(t, revoked)
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"gorm.io/gorm"
+)
+
+// productTag labels a product with one of its tags.
+type productTag struct {
+	ProductID string
+	Tag       string
+}
+
+func (productTag) TableName() string { return "product_tags" }
+
+type Tag struct {
+	DB *gorm.DB
+}
+
+func NewTag(db *gorm.DB) *Tag {
+	return &Tag{DB: db}
+}
+
+// FindAll returns every tag of a product that isn't deleted, the most used first.
+func (t *Tag) FindAll(ctx context.Context) ([]entity.TagUsage, error) {
+	var tags []entity.TagUsage
+	err := t.DB.WithContext(ctx).Model(&productTag{}).
+		Select("product_tags.tag AS tag, COUNT(*) AS products").
+		Joins("JOIN products ON products.id = product_tags.product_id AND products.deleted_at IS NULL").
+		Group("product_tags.tag").
+		Order("products DESC").
+		Order("tag").
+		Scan(&tags).Error
+	return tags, err
+}
+
+// insertTags stores the tags of products, which must not have any yet.
+func insertTags(tx *gorm.DB, products ...*entity.Product) error {
+	var tags []productTag
+	for _, product := range products {
+		for _, tag := range product.Tags {
+			tags = append(tags, productTag{ProductID: product.ID.String(), Tag: tag})
+		}
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(tags, createBatchSize).Error
+}
+
+// replaceTags stores the tags of product in place of the previous ones,
+// leaving them as they are when product.Tags is nil.
+func replaceTags(tx *gorm.DB, product *entity.Product) error {
+	if product.Tags == nil {
+		return nil
+	}
+	if err := tx.Where("product_id = ?", product.ID.String()).Delete(&productTag{}).Error; err != nil {
+		return err
+	}
+	return insertTags(tx, product)
+}
+
+// loadTags fills the tags of products with a single query.
+func loadTags(db *gorm.DB, products ...*entity.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	byID := make(map[string]*entity.Product, len(products))
+	ids := make([]string, len(products))
+	for i, product := range products {
+		product.Tags = []string{}
+		byID[product.ID.String()] = product
+		ids[i] = product.ID.String()
+	}
+
+	var tags []productTag
+	if err := db.Where("product_id IN ?", ids).Order("tag").Find(&tags).Error; err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		byID[tag.ProductID].Tags = append(byID[tag.ProductID].Tags, tag.Tag)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func createTaggedProduct(t *testing.T, productDB *Product, name string, tags ...string) *entity.Product {
+	ctx := context.Background()
+	product, err := entity.NewProduct(name, "", usd(1000))
+	assert.Nil(t, err)
+	assert.Nil(t, product.SetTags(tags))
+	assert.Nil(t, productDB.Create(ctx, product))
+	return product
+}
+
+func TestProduct_Tags(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	productDB := NewProduct(db)
+	product := createTaggedProduct(t, productDB, "Laptop", "new", "clearance")
+
+	found, err := productDB.FindByID(ctx, product.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"clearance", "new"}, found.Tags)
+
+	found.Tags = []string{"sale"}
+	assert.Nil(t, productDB.Update(ctx, found))
+	found, err = productDB.FindByID(ctx, product.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"sale"}, found.Tags)
+
+	found.Tags = nil
+	found.Name = "Notebook"
+	assert.Nil(t, productDB.Update(ctx, found))
+	found, err = productDB.FindByID(ctx, product.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"sale"}, found.Tags)
+
+	found.Version = 1
+	found.Tags = []string{"lost"}
+	assert.ErrorIs(t, productDB.Update(ctx, found), ErrVersionConflict)
+	found, _ = productDB.FindByID(ctx, product.ID.String())
+	assert.Equal(t, []string{"sale"}, found.Tags)
+
+	batched, err := entity.NewProduct("Mouse", "", usd(1000))
+	assert.Nil(t, err)
+	assert.Nil(t, batched.SetTags([]string{"new"}))
+	assert.Nil(t, productDB.CreateBatch(ctx, []*entity.Product{batched}))
+	found, err = productDB.FindByID(ctx, batched.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"new"}, found.Tags)
+}
+
+func TestProduct_SearchByTags(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	productDB := NewProduct(db)
+	createTaggedProduct(t, productDB, "Laptop", "new", "clearance")
+	createTaggedProduct(t, productDB, "Mouse", "new")
+	createTaggedProduct(t, productDB, "Novel")
+
+	products, err := productDB.Search(ctx, ProductFilter{Tags: []string{"new", "clearance"}}, 0, 0, "name")
+	assert.Nil(t, err)
+	assert.Len(t, products, 2)
+	assert.Equal(t, []string{"clearance", "new"}, products[0].Tags)
+	assert.Equal(t, []string{"new"}, products[1].Tags)
+
+	products, err = productDB.Search(ctx, ProductFilter{Tags: []string{"new", "clearance"}, AllTags: true}, 0, 0, "name")
+	assert.Nil(t, err)
+	assert.Len(t, products, 1)
+	assert.Equal(t, "Laptop", products[0].Name)
+
+	products, _, err = productDB.SearchByCursor(ctx, ProductFilter{}, nil, 10, "")
+	assert.Nil(t, err)
+	assert.Len(t, products, 3)
+	assert.Equal(t, []string{}, products[2].Tags)
+}
+
+func TestProduct_EachWithTags(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	productDB := NewProduct(db)
+	createTaggedProduct(t, productDB, "Laptop", "new", "clearance")
+	createTaggedProduct(t, productDB, "Mouse")
+	createTaggedProduct(t, productDB, "Novel", "books")
+
+	var names []string
+	var tags [][]string
+	err := productDB.Each(ctx, ProductFilter{}, "-name", func(product *entity.Product) error {
+		names = append(names, product.Name)
+		tags = append(tags, product.Tags)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Novel", "Mouse", "Laptop"}, names)
+	assert.Equal(t, [][]string{{"books"}, {}, {"clearance", "new"}}, tags)
+}
+
+func TestTag_FindAll(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	productDB := NewProduct(db)
+	createTaggedProduct(t, productDB, "Laptop", "new", "clearance")
+	createTaggedProduct(t, productDB, "Mouse", "new")
+	deleted := createTaggedProduct(t, productDB, "Keyboard", "clearance", "sale")
+	assert.Nil(t, productDB.Delete(ctx, deleted.ID.String()))
+
+	tags, err := NewTag(db).FindAll(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.TagUsage{{Tag: "new", Products: 2}, {Tag: "clearance", Products: 1}}, tags)
+
+	_, err = productDB.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+	assert.Nil(t, err)
+	var stored int64
+	db.Model(&productTag{}).Count(&stored)
+	assert.Equal(t, int64(3), stored)
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
 	"gorm.io/gorm"
 )
@@ -13,14 +14,42 @@ func NewUser(db *gorm.DB) *User {
 	return &User{DB: db}
 }
 
-func (u *User) Create(user *entity.User) error {
-	return u.DB.Create(user).Error
+// Create stores user and audits its creation.
+func (u *User) Create(ctx context.Context, user *entity.User) error {
+	return u.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, entity.AuditCreate, entity.AuditUser, user.ID.String(), nil, user)
+	})
 }
 
-func (u *User) FindByEmail(email string) (*entity.User, error) {
+func (u *User) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var user entity.User
-	if err := u.DB.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := u.DB.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
+
+func (u *User) FindByID(ctx context.Context, id string) (*entity.User, error) {
+	var user entity.User
+	if err := u.DB.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Update saves user and audits the change.
+func (u *User) Update(ctx context.Context, user *entity.User) error {
+	return u.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		before, err := NewUser(tx).FindByID(ctx, user.ID.String())
+		if err != nil {
+			return err
+		}
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, entity.AuditUpdate, entity.AuditUser, user.ID.String(), before, user)
+	})
+}
@@ -1,7 +1,9 @@
 package database
 
 import (
+	"context"
 	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
 	"github.com/SchunckLeonardo/go-expert-api/test/utils"
 	"github.com/stretchr/testify/assert"
 	"log"
@@ -9,12 +11,13 @@ import (
 )
 
 func TestUser_Create(t *testing.T) {
+	ctx := context.Background()
 	db := utils.OpenDBConnection(t)
 
 	user, _ := entity.NewUser("John Doe", "j@j.com", "123456")
 	userDB := NewUser(db)
 
-	err := userDB.Create(user)
+	err := userDB.Create(ctx, user)
 	assert.Nil(t, err)
 
 	var userFound entity.User
@@ -34,6 +37,7 @@ func TestUser_Create(t *testing.T) {
 }
 
 func TestUser_FindByEmail(t *testing.T) {
+	ctx := context.Background()
 	db := utils.OpenDBConnection(t)
 
 	email := "j@j.com"
@@ -41,9 +45,9 @@ func TestUser_FindByEmail(t *testing.T) {
 	user, _ := entity.NewUser("John Doe", email, "123456")
 	userDB := NewUser(db)
 
-	_ = userDB.Create(user)
+	_ = userDB.Create(ctx, user)
 
-	userFounded, err := userDB.FindByEmail(email)
+	userFounded, err := userDB.FindByEmail(ctx, email)
 	assert.Nil(t, err)
 	assert.NotNil(t, userFounded)
 
@@ -59,3 +63,40 @@ func TestUser_FindByEmail(t *testing.T) {
 	log.Println(err)
 	assert.NotNil(t, err)
 }
+
+func TestUser_FindByID(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	user, _ := entity.NewUser("John Doe", "j@j.com", "123456")
+	userDB := NewUser(db)
+
+	_ = userDB.Create(ctx, user)
+
+	userFounded, err := userDB.FindByID(ctx, user.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, user.ID, userFounded.ID)
+	assert.Equal(t, user.Email, userFounded.Email)
+	assert.Equal(t, entity.RoleViewer, userFounded.Role)
+
+	_, err = userDB.FindByID(ctx, entity2.NewID().String())
+	assert.NotNil(t, err)
+}
+
+func TestUser_Update(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+
+	user, _ := entity.NewUser("John Doe", "j@j.com", "123456")
+	userDB := NewUser(db)
+
+	_ = userDB.Create(ctx, user)
+
+	assert.Nil(t, user.SetRole(entity.RoleAdmin))
+	err := userDB.Update(ctx, user)
+	assert.Nil(t, err)
+
+	userFounded, err := userDB.FindByID(ctx, user.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, entity.RoleAdmin, userFounded.Role)
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"gorm.io/gorm"
+)
+
+var ErrSKUExists = errors.New("sku is already used by another variant")
+
+type Variant struct {
+	DB *gorm.DB
+}
+
+func NewVariant(db *gorm.DB) *Variant {
+	return &Variant{DB: db}
+}
+
+// Create stores variant. It returns ErrSKUExists when another variant, of any
+// product, has its SKU.
+func (v *Variant) Create(ctx context.Context, variant *entity.Variant) error {
+	return v.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkSKU(tx, variant); err != nil {
+			return err
+		}
+		return skuConflict(tx, tx.Create(variant).Error)
+	})
+}
+
+// FindByProduct returns the variants of a product ordered by SKU.
+func (v *Variant) FindByProduct(ctx context.Context, productID string) ([]entity.Variant, error) {
+	var variants []entity.Variant
+	err := v.DB.WithContext(ctx).Where("product_id = ?", productID).Order("sku").Find(&variants).Error
+	return variants, err
+}
+
+// FindByID finds a variant of the product productID.
+func (v *Variant) FindByID(ctx context.Context, productID, id string) (*entity.Variant, error) {
+	var variant entity.Variant
+	err := v.DB.WithContext(ctx).Where("id = ? AND product_id = ?", id, productID).First(&variant).Error
+	return &variant, err
+}
+
+func (v *Variant) FindBySKU(ctx context.Context, sku string) (*entity.Variant, error) {
+	var variant entity.Variant
+	err := v.DB.WithContext(ctx).Where("sku = ?", sku).First(&variant).Error
+	return &variant, err
+}
+
+// Update saves variant, clearing its price when it has none. It returns
+// ErrSKUExists when another variant has its SKU.
+func (v *Variant) Update(ctx context.Context, variant *entity.Variant) error {
+	return v.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkSKU(tx, variant); err != nil {
+			return err
+		}
+		clearPrice := variant.Price == nil
+		result := tx.Model(variant).Select("*").Omit("id", "product_id", "created_at").Updates(variant)
+		if result.Error != nil {
+			return skuConflict(tx, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if !clearPrice {
+			return nil
+		}
+		// gorm writes a nil embedded price as a zero one, allocating it on the way
+		variant.Price = nil
+		return tx.Exec("UPDATE variants SET price_amount = NULL, price_currency = NULL WHERE id = ?", variant.ID.String()).Error
+	})
+}
+
+func (v *Variant) Delete(ctx context.Context, id string) error {
+	result := v.DB.WithContext(ctx).Where("id = ?", id).Delete(&entity.Variant{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// checkSKU returns ErrSKUExists when a variant other than variant has its SKU.
+func checkSKU(tx *gorm.DB, variant *entity.Variant) error {
+	var count int64
+	err := tx.Model(&entity.Variant{}).Where("sku = ? AND id <> ?", variant.SKU, variant.ID.String()).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrSKUExists
+	}
+	return nil
+}
+
+// skuConflict turns the violation of the unique index on the SKU, left by a
+// variant stored concurrently with the same SKU, into ErrSKUExists.
+func skuConflict(tx *gorm.DB, err error) error {
+	if err == nil {
+		return nil
+	}
+	if duplicatedKey(tx, err) {
+		return ErrSKUExists
+	}
+	return err
+}
+
+// duplicatedKey reports whether err is the database refusing a row whose
+// unique key another row already has.
+func duplicatedKey(tx *gorm.DB, err error) bool {
+	translator, ok := tx.Dialector.(gorm.ErrorTranslator)
+	return ok && errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey)
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func createVariant(t *testing.T, variantDB *Variant, product *entity.Product, sku string, options map[string]string) *entity.Variant {
+	ctx := context.Background()
+	variant, err := entity.NewVariant(product.ID, sku, options, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, variantDB.Create(ctx, variant))
+	return variant
+}
+
+func TestVariant_CRUD(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+	productDB := NewProduct(db)
+	variantDB := NewVariant(db)
+
+	product, err := entity.NewProduct("Shirt", "", usd(2000))
+	assert.Nil(t, err)
+	assert.Nil(t, productDB.Create(ctx, product))
+	medium := createVariant(t, variantDB, product, "shirt-m", map[string]string{"size": "M"})
+	assert.Nil(t, medium.Price)
+	createVariant(t, variantDB, product, "shirt-l", map[string]string{"size": "L"})
+
+	variants, err := variantDB.FindByProduct(ctx, product.ID.String())
+	assert.Nil(t, err)
+	assert.Len(t, variants, 2)
+	assert.Equal(t, "SHIRT-L", variants[0].SKU)
+	assert.Equal(t, map[string]string{"size": "L"}, variants[0].Options)
+	assert.Nil(t, variants[0].Price)
+
+	price := usd(2500)
+	assert.Nil(t, medium.Set("SHIRT-M-BLUE", map[string]string{"size": "M", "color": "blue"}, &price))
+	assert.Nil(t, variantDB.Update(ctx, medium))
+	found, err := variantDB.FindBySKU(ctx, "SHIRT-M-BLUE")
+	assert.Nil(t, err)
+	assert.Equal(t, medium.ID, found.ID)
+	assert.Equal(t, &price, found.Price)
+	assert.Equal(t, "blue", found.Options["color"])
+
+	assert.Nil(t, found.Set(found.SKU, found.Options, nil))
+	assert.Nil(t, variantDB.Update(ctx, found))
+	assert.Nil(t, found.Price)
+	found, err = variantDB.FindByID(ctx, product.ID.String(), medium.ID.String())
+	assert.Nil(t, err)
+	assert.Nil(t, found.Price)
+	assert.Equal(t, product.Price, found.EffectivePrice(product))
+
+	_, err = variantDB.FindByID(ctx, medium.ID.String(), medium.ID.String())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	assert.Nil(t, variantDB.Delete(ctx, medium.ID.String()))
+	assert.ErrorIs(t, variantDB.Delete(ctx, medium.ID.String()), gorm.ErrRecordNotFound)
+	variants, err = variantDB.FindByProduct(ctx, product.ID.String())
+	assert.Nil(t, err)
+	assert.Len(t, variants, 1)
+}
+
+func TestVariant_UniqueSKU(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+	productDB := NewProduct(db)
+	variantDB := NewVariant(db)
+
+	shirt, err := entity.NewProduct("Shirt", "", usd(2000))
+	assert.Nil(t, err)
+	assert.Nil(t, productDB.Create(ctx, shirt))
+	pants, err := entity.NewProduct("Pants", "", usd(3000))
+	assert.Nil(t, err)
+	assert.Nil(t, productDB.Create(ctx, pants))
+
+	createVariant(t, variantDB, shirt, "SKU-1", nil)
+	other := createVariant(t, variantDB, shirt, "SKU-2", nil)
+
+	duplicated, err := entity.NewVariant(pants.ID, "sku-1", nil, nil)
+	assert.Nil(t, err)
+	assert.ErrorIs(t, variantDB.Create(ctx, duplicated), ErrSKUExists)
+
+	assert.Nil(t, other.Set("SKU-1", nil, nil))
+	assert.ErrorIs(t, variantDB.Update(ctx, other), ErrSKUExists)
+
+	// the unique index holds when the check is raced
+	assert.ErrorIs(t, skuConflict(db, db.Create(duplicated).Error), ErrSKUExists)
+}
+
+func TestProduct_PurgeDeletedVariants(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+	productDB := NewProduct(db)
+	variantDB := NewVariant(db)
+
+	product, err := entity.NewProduct("Shirt", "", usd(2000))
+	assert.Nil(t, err)
+	assert.Nil(t, productDB.Create(ctx, product))
+	createVariant(t, variantDB, product, "SKU-1", nil)
+
+	assert.Nil(t, productDB.Delete(ctx, product.ID.String()))
+	_, err = productDB.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+	assert.Nil(t, err)
+	_, err = variantDB.FindBySKU(ctx, "SKU-1")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"context"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/logging"
+	"github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"time"
+)
+
+// Every runs job each interval until ctx is done. Each run gets a request ID
+// of its own, which correlates its logs and the changes it makes. Errors are
+// logged and do not stop the following runs. The job doesn't run at all when
+// interval isn't positive.
+func Every(ctx context.Context, interval time.Duration, name string, job func(ctx context.Context) error) {
+	if interval <= 0 {
+		logging.FromContext(ctx).Error("job not started: its interval must be positive", "job", name, "interval", interval.String())
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runID := entity.NewID().String()
+			logger := logging.FromContext(ctx).With("job", name, "request_id", runID)
+			runCtx := logging.WithContext(logging.WithRequestID(ctx, runID), logger)
+			if err := job(runCtx); err != nil {
+				logger.Error("running job", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := New(&out, "warn")
+	assert.Nil(t, err)
+
+	logger.Info("ignored")
+	logger.Warn("slow", "duration_ms", 300)
+
+	var line map[string]interface{}
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "WARN", line["level"])
+	assert.Equal(t, "slow", line["msg"])
+	assert.Equal(t, float64(300), line["duration_ms"])
+
+	_, err = New(&out, "verbose")
+	assert.NotNil(t, err)
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, slog.Default(), FromContext(context.Background()))
+
+	logger, err := New(&bytes.Buffer{}, "info")
+	assert.Nil(t, err)
+	assert.Equal(t, logger, FromContext(WithContext(context.Background(), logger)))
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocal_PutAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	local, err := NewLocal(dir, "http://localhost:8080/images/")
+	assert.Nil(t, err)
+
+	err = local.Put(context.Background(), "products/1/a.png", strings.NewReader("png"), "image/png")
+	assert.Nil(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, "products", "1", "a.png"))
+	assert.Nil(t, err)
+	assert.Equal(t, "png", string(data))
+	assert.Equal(t, "http://localhost:8080/images/products/1/a.png", local.URL("products/1/a.png"))
+
+	assert.Nil(t, local.Delete(context.Background(), "products/1/a.png"))
+	_, err = os.Stat(filepath.Join(dir, "products", "1", "a.png"))
+	assert.True(t, os.IsNotExist(err))
+	assert.Nil(t, local.Delete(context.Background(), "products/1/a.png"))
+}
+
+func TestLocal_InvalidKey(t *testing.T) {
+	local, err := NewLocal(t.TempDir(), "/images")
+	assert.Nil(t, err)
+
+	for _, key := range []string{"", "../a.png", "/etc/passwd", "products/../../a.png", "products//a.png"} {
+		err = local.Put(context.Background(), key, strings.NewReader("x"), "image/png")
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+		assert.ErrorIs(t, local.Delete(context.Background(), key), ErrInvalidKey, key)
+	}
+}
+
+func TestLocal_Handler(t *testing.T) {
+	local, err := NewLocal(t.TempDir(), "/images")
+	assert.Nil(t, err)
+	assert.Nil(t, local.Put(context.Background(), "products/1/a.png", strings.NewReader("png"), "image/png"))
+	handler := http.StripPrefix("/images/", local.Handler())
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/images/products/1/a.png", nil))
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "png", response.Body.String())
+	assert.Equal(t, "nosniff", response.Header().Get("X-Content-Type-Options"))
+
+	for _, target := range []string{"/images/products/1/", "/images/products", "/images/products/1/b.png"} {
+		response = httptest.NewRecorder()
+		handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusNotFound, response.Code, target)
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"net/http"
+	"strconv"
+)
+
+var (
+	ErrInvalidAuditAction   = errors.New("action must be create, update, delete or restore")
+	ErrInvalidAuditResource = errors.New("resource_type must be product, product_categories or user")
+	ErrInvalidAuditRange    = errors.New("from can't be after to")
+)
+
+type AuditHandler struct {
+	AuditDB database.AuditInterface
+}
+
+func NewAuditHandler(db database.AuditInterface) *AuditHandler {
+	return &AuditHandler{AuditDB: db}
+}
+
+// ListAuditEntries godoc
+//
+//	@Summary		List the audit log
+//	@Description	List the changes made to products and users, the latest first, with who made them and the resource before and after each one. Changes to the categories of a product are audited as updates of a product_categories resource with the product ID and its category_ids. Changes made by the server itself, like applying scheduled prices, have an empty actor_id and the request_id of the job run
+//	@Tags			audit
+//	@Produce		json
+//	@Param			actor_id		query		string	false	"user who made the change"	Format(uuid)
+//	@Param			action			query		string	false	"kind of change"			Enums(create, update, delete, restore)
+//	@Param			resource_type	query		string	false	"kind of resource changed"	Enums(product, product_categories, user)
+//	@Param			resource_id		query		string	false	"resource changed"			Format(uuid)
+//	@Param			request_id		query		string	false	"request that made the change"
+//	@Param			from			query		string	false	"made at or after, RFC 3339 or YYYY-MM-DD"
+//	@Param			to				query		string	false	"made at or before, RFC 3339 or YYYY-MM-DD"
+//	@Param			page			query		string	false	"page number"
+//	@Param			limit			query		string	false	"amount items"
+//	@Success		200				{array}		entity.AuditEntry
+//	@Failure		400				{object}	entity.Error
+//	@Failure		403				{object}	entity.Error
+//	@Failure		500				{object}	entity.Error
+//	@Router			/audit [get]
+//	@Security		ApiKeyAuth
+func (h *AuditHandler) ListAuditEntries(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	entries, err := h.AuditDB.Search(r.Context(), filter, page, limit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(entries)
+}
+
+func parseAuditFilter(r *http.Request) (database.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := database.AuditFilter{
+		ActorID:      query.Get("actor_id"),
+		Action:       entity.AuditAction(query.Get("action")),
+		ResourceType: entity.AuditResource(query.Get("resource_type")),
+		ResourceID:   query.Get("resource_id"),
+		RequestID:    query.Get("request_id"),
+	}
+	if filter.Action != "" && !filter.Action.IsValid() {
+		return filter, ErrInvalidAuditAction
+	}
+	if filter.ResourceType != "" && !filter.ResourceType.IsValid() {
+		return filter, ErrInvalidAuditResource
+	}
+
+	var err error
+	if filter.From, err = parseDateParam(query.Get("from"), false); err != nil {
+		return filter, fmt.Errorf("invalid from: %w", err)
+	}
+	if filter.To, err = parseDateParam(query.Get("to"), true); err != nil {
+		return filter, fmt.Errorf("invalid to: %w", err)
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return filter, ErrInvalidAuditRange
+	}
+	return filter, nil
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseAuditFilter(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/audit?action=delete&resource_type=product&resource_id=1&actor_id=2&request_id=3&from=2024-01-01&to=2024-01-31", nil)
+	filter, err := parseAuditFilter(r)
+	assert.Nil(t, err)
+	assert.Equal(t, entity.AuditDelete, filter.Action)
+	assert.Equal(t, entity.AuditProduct, filter.ResourceType)
+	assert.Equal(t, "1", filter.ResourceID)
+	assert.Equal(t, "2", filter.ActorID)
+	assert.Equal(t, "3", filter.RequestID)
+	assert.Equal(t, "2024-01-01T00:00:00Z", filter.From.Format("2006-01-02T15:04:05Z07:00"))
+	assert.Equal(t, 31, filter.To.Day())
+
+	for target, expected := range map[string]error{
+		"/audit?action=read":                   ErrInvalidAuditAction,
+		"/audit?resource_type=category":        ErrInvalidAuditResource,
+		"/audit?from=2024-02-01&to=2024-01-01": ErrInvalidAuditRange,
+	} {
+		_, err = parseAuditFilter(httptest.NewRequest(http.MethodGet, target, nil))
+		assert.ErrorIs(t, err, expected, target)
+	}
+	_, err = parseAuditFilter(httptest.NewRequest(http.MethodGet, "/audit?from=yesterday", nil))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,386 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/internal/dto"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type CategoryHandler struct {
+	CategoryDB database.CategoryInterface
+	ProductDB  database.ProductInterface
+}
+
+func NewCategoryHandler(db database.CategoryInterface, productDB database.ProductInterface) *CategoryHandler {
+	return &CategoryHandler{CategoryDB: db, ProductDB: productDB}
+}
+
+// CreateCategory godoc
+//
+//	@Summary		Create a category
+//	@Description	Create a category, below parent_id when it is set
+//	@Tags			categories
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.CreateCategoryInput	true	"category request"
+//	@Success		201		{object}	entity.Category
+//	@Failure		400		{object}	entity.Error
+//	@Failure		500		{object}	entity.Error
+//	@Router			/categories [post]
+//	@Security		ApiKeyAuth
+func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var categoryDTO dto.CreateCategoryInput
+	err := json.NewDecoder(r.Body).Decode(&categoryDTO)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	parentID, err := parseParentID(categoryDTO.ParentID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	category, err := entity.NewCategory(categoryDTO.Name, parentID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = h.CategoryDB.Create(r.Context(), category)
+	if err != nil {
+		w.WriteHeader(categoryErrorStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(category)
+}
+
+// ListCategories godoc
+//
+//	@Summary		List categories
+//	@Description	List every category ordered by name, with the id of its parent
+//	@Tags			categories
+//	@Produce		json
+//	@Success		200	{array}		entity.Category
+//	@Failure		500	{object}	entity.Error
+//	@Router			/categories [get]
+//	@Security		ApiKeyAuth
+func (h *CategoryHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.CategoryDB.FindAll(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(categories)
+}
+
+// GetCategoryTree godoc
+//
+//	@Summary		Get the category tree
+//	@Description	Get the root categories with their children nested, to build navigation menus
+//	@Tags			categories
+//	@Produce		json
+//	@Success		200	{array}		dto.CategoryTreeNode
+//	@Failure		500	{object}	entity.Error
+//	@Router			/categories/tree [get]
+//	@Security		ApiKeyAuth
+func (h *CategoryHandler) GetCategoryTree(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.CategoryDB.FindAll(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(categoryTree(categories))
+}
+
+// GetCategory godoc
+//
+//	@Summary		Get a category
+//	@Description	Get a category
+//	@Tags			categories
+//	@Produce		json
+//	@Param			id	path		string	true	"category ID"	Format(uuid)
+//	@Success		200	{object}	entity.Category
+//	@Failure		404	{object}	entity.Error
+//	@Router			/categories/{id} [get]
+//	@Security		ApiKeyAuth
+func (h *CategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	category, err := h.CategoryDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(category)
+}
+
+// UpdateCategory godoc
+//
+//	@Summary		Update a category
+//	@Description	Rename a category or move it below another parent, or to the root when parent_id is empty
+//	@Tags			categories
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string					true	"category ID"	Format(uuid)
+//	@Param			request	body		dto.UpdateCategoryInput	true	"category request"
+//	@Success		200		{object}	entity.Category
+//	@Failure		400		{object}	entity.Error
+//	@Failure		404		{object}	entity.Error
+//	@Failure		500		{object}	entity.Error
+//	@Router			/categories/{id} [put]
+//	@Security		ApiKeyAuth
+func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	category, err := h.CategoryDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var categoryDTO dto.UpdateCategoryInput
+	err = json.NewDecoder(r.Body).Decode(&categoryDTO)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	category.ParentID, err = parseParentID(categoryDTO.ParentID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	category.Name = categoryDTO.Name
+	if err = category.Validate(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = h.CategoryDB.Update(r.Context(), category)
+	if err != nil {
+		w.WriteHeader(categoryErrorStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(category)
+}
+
+// DeleteCategory godoc
+//
+//	@Summary		Delete a category
+//	@Description	Delete a category without children, its products are kept
+//	@Tags			categories
+//	@Param			id	path	string	true	"category ID"	Format(uuid)
+//	@Success		200
+//	@Failure		404	{object}	entity.Error
+//	@Failure		409	{object}	entity.Error
+//	@Failure		500	{object}	entity.Error
+//	@Router			/categories/{id} [delete]
+//	@Security		ApiKeyAuth
+func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	err := h.CategoryDB.Delete(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(categoryErrorStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetProductCategories godoc
+//
+//	@Summary		List the categories of a product
+//	@Description	List the categories a product is directly in
+//	@Tags			categories
+//	@Produce		json
+//	@Param			id	path		string	true	"product ID"	Format(uuid)
+//	@Success		200	{array}		entity.Category
+//	@Failure		404	{object}	entity.Error
+//	@Failure		500	{object}	entity.Error
+//	@Router			/products/{id}/categories [get]
+//	@Security		ApiKeyAuth
+func (h *CategoryHandler) GetProductCategories(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	categories, err := h.CategoryDB.FindByProduct(r.Context(), product.ID.String())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(categories)
+}
+
+// SetProductCategories godoc
+//
+//	@Summary		Set the categories of a product
+//	@Description	Replace the categories of a product, an empty list removes it from all of them
+//	@Tags			categories
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string							true	"product ID"	Format(uuid)
+//	@Param			request	body		dto.SetProductCategoriesInput	true	"categories request"
+//	@Success		200		{array}		entity.Category
+//	@Failure		400		{object}	entity.Error
+//	@Failure		403		{object}	entity.Error
+//	@Failure		404		{object}	entity.Error
+//	@Failure		500		{object}	entity.Error
+//	@Router			/products/{id}/categories [put]
+//	@Security		ApiKeyAuth
+func (h *CategoryHandler) SetProductCategories(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if !canModify(r, product) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := entity2.Error{Message: ErrNotProductOwner.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var categoriesDTO dto.SetProductCategoriesInput
+	err = json.NewDecoder(r.Body).Decode(&categoriesDTO)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	for _, id := range categoriesDTO.CategoryIDs {
+		if _, err = entity2.ParseID(id); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			errorResponse := entity2.Error{Message: fmt.Sprintf("%s: %q", entity.ErrInvalidID, id)}
+			_ = json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+	}
+
+	err = h.CategoryDB.SetProductCategories(r.Context(), product.ID.String(), categoriesDTO.CategoryIDs)
+	if err != nil {
+		w.WriteHeader(categoryErrorStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	categories, err := h.CategoryDB.FindByProduct(r.Context(), product.ID.String())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(categories)
+}
+
+// parseParentID parses the parent of a category, which is a root one when
+// value is nil or empty.
+func parseParentID(value *string) (*entity2.ID, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	id, err := entity2.ParseID(*value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parent_id: %w", err)
+	}
+	return &id, nil
+}
+
+func categoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, database.ErrCategoryHasChildren):
+		return http.StatusConflict
+	case errors.Is(err, database.ErrParentCategoryNotFound),
+		errors.Is(err, database.ErrCategoryNotFound),
+		errors.Is(err, entity.ErrCategoryCycle):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// categoryTree nests categories below their parent, keeping their order.
+func categoryTree(categories []entity.Category) []*dto.CategoryTreeNode {
+	nodes := make(map[entity2.ID]*dto.CategoryTreeNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &dto.CategoryTreeNode{Category: category, Children: []*dto.CategoryTreeNode{}}
+	}
+
+	roots := []*dto.CategoryTreeNode{}
+	for _, category := range categories {
+		var parent *dto.CategoryTreeNode
+		if category.ParentID != nil {
+			parent = nodes[*category.ParentID]
+		}
+		if parent != nil {
+			parent.Children = append(parent.Children, nodes[category.ID])
+		} else {
+			roots = append(roots, nodes[category.ID])
+		}
+	}
+	return roots
+}
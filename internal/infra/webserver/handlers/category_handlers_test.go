@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCategoryTree(t *testing.T) {
+	books, _ := entity.NewCategory("Books", nil)
+	electronics, _ := entity.NewCategory("Electronics", nil)
+	laptops, _ := entity.NewCategory("Laptops", &electronics.ID)
+	phones, _ := entity.NewCategory("Phones", &electronics.ID)
+	gaming, _ := entity.NewCategory("Gaming", &laptops.ID)
+
+	tree := categoryTree([]entity.Category{*books, *electronics, *gaming, *laptops, *phones})
+
+	assert.Len(t, tree, 2)
+	assert.Equal(t, "Books", tree[0].Name)
+	assert.Empty(t, tree[0].Children)
+	assert.Equal(t, "Electronics", tree[1].Name)
+	assert.Len(t, tree[1].Children, 2)
+	assert.Equal(t, "Laptops", tree[1].Children[0].Name)
+	assert.Equal(t, "Gaming", tree[1].Children[0].Children[0].Name)
+	assert.Equal(t, "Phones", tree[1].Children[1].Name)
+}
+
+func TestParseParentID(t *testing.T) {
+	id, err := parseParentID(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, id)
+
+	empty := ""
+	id, err = parseParentID(&empty)
+	assert.Nil(t, err)
+	assert.Nil(t, id)
+
+	invalid := "abc"
+	_, err = parseParentID(&invalid)
+	assert.Error(t, err)
+}
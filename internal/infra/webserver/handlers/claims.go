@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/go-chi/jwtauth"
+	"net/http"
+)
+
+// currentUser returns the subject and role claims of the JWT verified for r.
+func currentUser(r *http.Request) (string, entity.Role) {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	userID, _ := claims["sub"].(string)
+	role, _ := claims["role"].(string)
+	return userID, entity.Role(role)
+}
+
+// canModify reports whether the user of r may change product: its owner or an admin.
+func canModify(r *http.Request, product *entity.Product) bool {
+	userID, role := currentUser(r)
+	return role == entity.RoleAdmin || product.IsOwnedBy(userID)
+}
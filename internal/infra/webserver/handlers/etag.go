@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrIfMatchRequired = errors.New("If-Match header is required")
+	ErrInvalidIfMatch  = errors.New("invalid If-Match header")
+	ErrVersionMismatch = errors.New("product was changed, fetch it again")
+)
+
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion returns the version in the If-Match header of r, or
+// current when it is "*".
+func ifMatchVersion(r *http.Request, current int) (int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" {
+		return 0, ErrIfMatchRequired
+	}
+	if value == "*" {
+		return current, nil
+	}
+
+	value = strings.TrimPrefix(value, "W/")
+	version, err := strconv.Atoi(strings.Trim(value, `"`))
+	if err != nil {
+		return 0, ErrInvalidIfMatch
+	}
+	return version, nil
+}
+
+// checkIfMatch writes the precondition error of r and returns false when its
+// If-Match header is missing, invalid or doesn't match current.
+func checkIfMatch(w http.ResponseWriter, r *http.Request, current int) bool {
+	version, err := ifMatchVersion(r, current)
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, ErrIfMatchRequired):
+		status = http.StatusPreconditionRequired
+	case err == nil && version != current:
+		status, err = http.StatusPreconditionFailed, ErrVersionMismatch
+	}
+	if err == nil {
+		return true
+	}
+
+	w.WriteHeader(status)
+	errorResponse := entity2.Error{Message: err.Error()}
+	_ = json.NewEncoder(w).Encode(errorResponse)
+	return false
+}
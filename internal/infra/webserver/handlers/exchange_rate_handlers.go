@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/SchunckLeonardo/go-expert-api/internal/dto"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type ExchangeRateHandler struct {
+	ExchangeRateDB database.ExchangeRateInterface
+}
+
+func NewExchangeRateHandler(db database.ExchangeRateInterface) *ExchangeRateHandler {
+	return &ExchangeRateHandler{ExchangeRateDB: db}
+}
+
+// CreateExchangeRate godoc
+//
+//	@Summary		Create an exchange rate
+//	@Description	Set how many units of a currency one USD buys, and how prices converted to it are rounded
+//	@Tags			exchange rates
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.CreateExchangeRateInput	true	"exchange rate request"
+//	@Success		201		{object}	entity.ExchangeRate
+//	@Failure		400		{object}	entity.Error
+//	@Failure		409		{object}	entity.Error
+//	@Failure		500		{object}	entity.Error
+//	@Router			/exchange-rates [post]
+//	@Security		ApiKeyAuth
+func (h *ExchangeRateHandler) CreateExchangeRate(w http.ResponseWriter, r *http.Request) {
+	var rateDTO dto.CreateExchangeRateInput
+	err := json.NewDecoder(r.Body).Decode(&rateDTO)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	rate, err := entity.NewExchangeRate(rateDTO.Currency, rateDTO.Rate.String(), exchangeRateRounding(rateDTO.UpdateExchangeRateInput))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = h.ExchangeRateDB.Create(r.Context(), rate)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrExchangeRateExists) {
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(rate)
+}
+
+// ListExchangeRates godoc
+//
+//	@Summary		List exchange rates
+//	@Description	List the exchange rates against USD used to convert prices
+//	@Tags			exchange rates
+//	@Produce		json
+//	@Success		200	{array}		entity.ExchangeRate
+//	@Failure		500	{object}	entity.Error
+//	@Router			/exchange-rates [get]
+//	@Security		ApiKeyAuth
+func (h *ExchangeRateHandler) ListExchangeRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.ExchangeRateDB.FindAll(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(rates)
+}
+
+// GetExchangeRate godoc
+//
+//	@Summary		Get an exchange rate
+//	@Description	Get the exchange rate of a currency
+//	@Tags			exchange rates
+//	@Produce		json
+//	@Param			currency	path		string	true	"ISO 4217 currency code"
+//	@Success		200			{object}	entity.ExchangeRate
+//	@Failure		404			{object}	entity.Error
+//	@Router			/exchange-rates/{currency} [get]
+//	@Security		ApiKeyAuth
+func (h *ExchangeRateHandler) GetExchangeRate(w http.ResponseWriter, r *http.Request) {
+	rate, err := h.ExchangeRateDB.FindByCurrency(r.Context(), strings.ToUpper(chi.URLParam(r, "currency")))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(rate)
+}
+
+// UpdateExchangeRate godoc
+//
+//	@Summary		Update an exchange rate
+//	@Description	Change the rate and rounding of a currency
+//	@Tags			exchange rates
+//	@Accept			json
+//	@Produce		json
+//	@Param			currency	path		string						true	"ISO 4217 currency code"
+//	@Param			request		body		dto.UpdateExchangeRateInput	true	"exchange rate request"
+//	@Success		200			{object}	entity.ExchangeRate
+//	@Failure		400			{object}	entity.Error
+//	@Failure		404			{object}	entity.Error
+//	@Failure		500			{object}	entity.Error
+//	@Router			/exchange-rates/{currency} [put]
+//	@Security		ApiKeyAuth
+func (h *ExchangeRateHandler) UpdateExchangeRate(w http.ResponseWriter, r *http.Request) {
+	rate, err := h.ExchangeRateDB.FindByCurrency(r.Context(), strings.ToUpper(chi.URLParam(r, "currency")))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var rateDTO dto.UpdateExchangeRateInput
+	err = json.NewDecoder(r.Body).Decode(&rateDTO)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	roundingDTO := exchangeRateRounding(rateDTO)
+	rate.Rate = rateDTO.Rate.String()
+	rate.RoundingMode = roundingDTO.Mode
+	rate.RoundingIncrement = roundingDTO.Increment
+	rate.UpdatedAt = time.Now()
+	if err = rate.Validate(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = h.ExchangeRateDB.Update(r.Context(), rate)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(rate)
+}
+
+// DeleteExchangeRate godoc
+//
+//	@Summary		Delete an exchange rate
+//	@Description	Delete the exchange rate of a currency, prices can't be converted to it or from it anymore
+//	@Tags			exchange rates
+//	@Param			currency	path	string	true	"ISO 4217 currency code"
+//	@Success		200
+//	@Failure		404	{object}	entity.Error
+//	@Failure		500	{object}	entity.Error
+//	@Router			/exchange-rates/{currency} [delete]
+//	@Security		ApiKeyAuth
+func (h *ExchangeRateHandler) DeleteExchangeRate(w http.ResponseWriter, r *http.Request) {
+	err := h.ExchangeRateDB.Delete(r.Context(), strings.ToUpper(chi.URLParam(r, "currency")))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// exchangeRateRounding returns the rounding of input, rounding half up to the minor unit
+// when it isn't set.
+func exchangeRateRounding(input dto.UpdateExchangeRateInput) entity2.Rounding {
+	rounding := entity2.Rounding{Mode: entity2.RoundingMode(input.RoundingMode), Increment: input.RoundingIncrement}
+	if rounding.Mode == "" {
+		rounding.Mode = entity2.DefaultRounding.Mode
+	}
+	if rounding.Increment == 0 {
+		rounding.Increment = entity2.DefaultRounding.Increment
+	}
+	return rounding
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestCreateExchangeRate_Conflict(t *testing.T) {
+	h := NewExchangeRateHandler(database.NewExchangeRate(utils.OpenDBConnection(t)))
+	router := newTestRouter()
+	router.Post("/exchange-rates", h.CreateExchangeRate)
+	admin := entity2.NewID()
+
+	w := serveAs(t, router, admin, entity.RoleAdmin, http.MethodPost, "/exchange-rates", map[string]string{"currency": "eur", "rate": "0.92"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = serveAs(t, router, admin, entity.RoleAdmin, http.MethodPost, "/exchange-rates", map[string]string{"currency": "EUR", "rate": "0.95"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, database.ErrExchangeRateExists.Error(), decodeError(t, w))
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/logging"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/storage"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/SchunckLeonardo/go-expert-api/pkg/imaging"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// ThumbnailSize is the largest side of the thumbnails of product images, in pixels.
+const ThumbnailSize = 256
+
+// ImageFormField is the multipart field the image is uploaded in.
+const ImageFormField = "image"
+
+// multipartOverhead is how many bytes an upload may have besides the image,
+// for the boundaries, part headers and other fields.
+const multipartOverhead = 64 << 10
+
+var (
+	ErrNotMultipart   = errors.New("images must be uploaded as multipart/form-data")
+	ErrImageRequired  = errors.New("an image file is required in the " + ImageFormField + " field")
+	ErrImageTooLarge  = errors.New("image is too large")
+	ErrImageNotStored = errors.New("image could not be stored")
+)
+
+type ImageHandler struct {
+	ImageDB   database.ImageInterface
+	ProductDB database.ProductInterface
+	Storage   storage.Storage
+	// MaxSize is the largest image file accepted, in bytes
+	MaxSize int64
+}
+
+func NewImageHandler(db database.ImageInterface, productDB database.ProductInterface, store storage.Storage, maxSize int64) *ImageHandler {
+	return &ImageHandler{ImageDB: db, ProductDB: productDB, Storage: store, MaxSize: maxSize}
+}
+
+// UploadProductImage godoc
+//
+//	@Summary		Upload a product image
+//	@Description	Upload a JPEG, PNG or GIF image of a product in the image field of a multipart form. The format is detected from the content, and a thumbnail is generated along with it
+//	@Tags			products
+//	@Accept			mpfd
+//	@Produce		json
+//	@Param			id		path		string	true	"product ID"	Format(uuid)
+//	@Param			image	formData	file	true	"image file"
+//	@Success		201		{object}	entity.ProductImage
+//	@Failure		400		{object}	entity.Error
+//	@Failure		403		{object}	entity.Error
+//	@Failure		404		{object}	entity.Error
+//	@Failure		413		{object}	entity.Error
+//	@Failure		415		{object}	entity.Error
+//	@Failure		500		{object}	entity.Error
+//	@Router			/products/{id}/images [post]
+//	@Security		ApiKeyAuth
+func (h *ImageHandler) UploadProductImage(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if !canModify(r, product) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := entity2.Error{Message: ErrNotProductOwner.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.MaxSize+multipartOverhead)
+	data, err := readImagePart(r, h.MaxSize)
+	if err != nil {
+		w.WriteHeader(imageErrorStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	decoded, err := imaging.Decode(data)
+	if err != nil {
+		w.WriteHeader(imageErrorStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	bounds := decoded.Bounds()
+	image, err := entity.NewProductImage(product.ID, decoded.ContentType, int64(len(data)), bounds.Dx(), bounds.Dy())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var thumbnail bytes.Buffer
+	thumbnailType, err := imaging.Encode(&thumbnail, imaging.Thumbnail(decoded, ThumbnailSize), decoded.ContentType)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	image.Key = image.StorageKey("", imaging.Extension(decoded.ContentType))
+	image.ThumbnailKey = image.StorageKey("_thumb", imaging.Extension(thumbnailType))
+	image.URL = h.Storage.URL(image.Key)
+	image.ThumbnailURL = h.Storage.URL(image.ThumbnailKey)
+
+	err = h.Storage.Put(r.Context(), image.Key, bytes.NewReader(data), decoded.ContentType)
+	if err == nil {
+		err = h.Storage.Put(r.Context(), image.ThumbnailKey, &thumbnail, thumbnailType)
+	}
+	if err == nil {
+		err = h.ImageDB.Create(r.Context(), image)
+	}
+	if err != nil {
+		logging.FromContext(r.Context()).Error("storing image", "key", image.Key, "error", err)
+		h.deleteBlobs(context.WithoutCancel(r.Context()), image)
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: ErrImageNotStored.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(image)
+}
+
+// DeleteProductImage godoc
+//
+//	@Summary		Delete a product image
+//	@Description	Delete an image of a product along with its thumbnail
+//	@Tags			products
+//	@Param			id			path	string	true	"product ID"	Format(uuid)
+//	@Param			image_id	path	string	true	"image ID"		Format(uuid)
+//	@Success		200
+//	@Failure		403	{object}	entity.Error
+//	@Failure		404	{object}	entity.Error
+//	@Failure		500	{object}	entity.Error
+//	@Router			/products/{id}/images/{image_id} [delete]
+//	@Security		ApiKeyAuth
+func (h *ImageHandler) DeleteProductImage(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if !canModify(r, product) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := entity2.Error{Message: ErrNotProductOwner.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	image, err := h.ImageDB.FindByID(r.Context(), product.ID.String(), chi.URLParam(r, "image_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = h.ImageDB.Delete(r.Context(), image.ID.String())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	h.deleteBlobs(context.WithoutCancel(r.Context()), image)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// PurgeOrphanedImages removes the blobs of the images of purged products,
+// then the images themselves, returning how many were removed.
+func (h *ImageHandler) PurgeOrphanedImages(ctx context.Context) (int, error) {
+	images, err := h.ImageDB.FindOrphaned(ctx, 1000)
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for i := range images {
+		if err = h.Storage.Delete(ctx, images[i].Key); err != nil {
+			return purged, err
+		}
+		if err = h.Storage.Delete(ctx, images[i].ThumbnailKey); err != nil {
+			return purged, err
+		}
+		if err = h.ImageDB.Delete(ctx, images[i].ID.String()); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// deleteBlobs removes the image and thumbnail of image from the storage,
+// logging the errors, as the image is gone for the clients anyway.
+func (h *ImageHandler) deleteBlobs(ctx context.Context, image *entity.ProductImage) {
+	for _, key := range []string{image.Key, image.ThumbnailKey} {
+		if err := h.Storage.Delete(ctx, key); err != nil {
+			logging.FromContext(ctx).Error("deleting blob", "key", key, "error", err)
+		}
+	}
+}
+
+// readImagePart reads the file in the image field of the multipart form of r,
+// failing with ErrImageTooLarge when it has more than maxSize bytes.
+func readImagePart(r *http.Request, maxSize int64) ([]byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return nil, ErrNotMultipart
+	}
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, ErrImageRequired
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() != ImageFormField || part.FileName() == "" {
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(part, maxSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(data)) > maxSize {
+			return nil, fmt.Errorf("%w, the limit is %d bytes", ErrImageTooLarge, maxSize)
+		}
+		if len(data) == 0 {
+			return nil, ErrImageRequired
+		}
+		return data, nil
+	}
+}
+
+func imageErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, ErrImageTooLarge), errors.Is(err, imaging.ErrTooManyPixels), errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrNotMultipart), errors.Is(err, imaging.ErrUnsupportedFormat):
+		return http.StatusUnsupportedMediaType
+	}
+	return http.StatusBadRequest
+}
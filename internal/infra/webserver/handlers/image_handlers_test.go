@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"github.com/SchunckLeonardo/go-expert-api/pkg/imaging"
+	"github.com/stretchr/testify/assert"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func multipartRequest(t *testing.T, field, fileName, content string) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	assert.Nil(t, writer.WriteField("caption", "front"))
+	part, err := writer.CreateFormFile(field, fileName)
+	assert.Nil(t, err)
+	_, err = part.Write([]byte(content))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/products/1/images", &body)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	return r
+}
+
+func TestReadImagePart(t *testing.T) {
+	data, err := readImagePart(multipartRequest(t, "image", "photo.png", "0123456789"), 10)
+	assert.Nil(t, err)
+	assert.Equal(t, "0123456789", string(data))
+
+	_, err = readImagePart(multipartRequest(t, "image", "photo.png", "0123456789"), 9)
+	assert.ErrorIs(t, err, ErrImageTooLarge)
+
+	_, err = readImagePart(multipartRequest(t, "photo", "photo.png", "0123456789"), 10)
+	assert.ErrorIs(t, err, ErrImageRequired)
+
+	_, err = readImagePart(multipartRequest(t, "image", "photo.png", ""), 10)
+	assert.ErrorIs(t, err, ErrImageRequired)
+
+	r := httptest.NewRequest(http.MethodPost, "/products/1/images", strings.NewReader("0123456789"))
+	r.Header.Set("Content-Type", "image/png")
+	_, err = readImagePart(r, 10)
+	assert.ErrorIs(t, err, ErrNotMultipart)
+}
+
+func TestImageErrorStatus(t *testing.T) {
+	assert.Equal(t, http.StatusRequestEntityTooLarge, imageErrorStatus(ErrImageTooLarge))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, imageErrorStatus(imaging.ErrTooManyPixels))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, imageErrorStatus(&http.MaxBytesError{Limit: 10}))
+	assert.Equal(t, http.StatusUnsupportedMediaType, imageErrorStatus(ErrNotMultipart))
+	assert.Equal(t, http.StatusUnsupportedMediaType, imageErrorStatus(imaging.ErrUnsupportedFormat))
+	assert.Equal(t, http.StatusBadRequest, imageErrorStatus(imaging.ErrInvalidImage))
+	assert.Equal(t, http.StatusBadRequest, imageErrorStatus(errors.New("multipart: NextPart: EOF")))
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/internal/dto"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultReservationTTL is how long a reservation holds stock when the request doesn't say.
+const DefaultReservationTTL = 15 * time.Minute
+
+var ErrReservedProductNotFound = errors.New("reserved product not found")
+
+type InventoryHandler struct {
+	InventoryDB database.InventoryInterface
+	ProductDB   database.ProductInterface
+}
+
+func NewInventoryHandler(db database.InventoryInterface, productDB database.ProductInterface) *InventoryHandler {
+	return &InventoryHandler{InventoryDB: db, ProductDB: productDB}
+}
+
+// GetInventory godoc
+//
+//	@Summary		Get the stock of a product
+//	@Description	Get the units on hand, reserved and available of a product
+//	@Tags			inventory
+//	@Produce		json
+//	@Param			id	path		string	true	"product ID"	Format(uuid)
+//	@Success		200	{object}	entity.Inventory
+//	@Failure		404	{object}	entity.Error
+//	@Failure		500	{object}	entity.Error
+//	@Router			/products/{id}/inventory [get]
+//	@Security		ApiKeyAuth
+func (h *InventoryHandler) GetInventory(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	inventory, err := h.InventoryDB.FindByProduct(r.Context(), product.ID.String())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(inventory)
+}
+
+// AdjustStock godoc
+//
+//	@Summary		Adjust the stock of a product
+//	@Description	Add or remove units on hand with a reason, which can't leave fewer units than are reserved
+//	@Tags			inventory
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string					true	"product ID"	Format(uuid)
+//	@Param			request	body		dto.AdjustStockInput	true	"adjustment request"
+//	@Success		200		{object}	entity.Inventory
+//	@Failure		400		{object}	entity.Error
+//	@Failure		404		{object}	entity.Error
+//	@Failure		409		{object}	entity.Error
+//	@Failure		500		{object}	entity.Error
+//	@Router			/products/{id}/inventory/adjustments [post]
+//	@Security		ApiKeyAuth
+func (h *InventoryHandler) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var adjustmentDTO dto.AdjustStockInput
+	err = json.NewDecoder(r.Body).Decode(&adjustmentDTO)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	userID, _ := currentUser(r)
+	adjustment, err := entity.NewStockAdjustment(product.ID, adjustmentDTO.Delta, entity.StockReason(adjustmentDTO.Reason), adjustmentDTO.Note, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	inventory, err := h.InventoryDB.Adjust(r.Context(), adjustment)
+	if err != nil {
+		w.WriteHeader(inventoryErrorStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(inventory)
+}
+
+// ListStockAdjustments godoc
+//
+//	@Summary		List the stock adjustments of a product
+//	@Description	List the changes to the stock of a product, the latest first
+//	@Tags			inventory
+//	@Produce		json
+//	@Param			id		path		string	true	"product ID"	Format(uuid)
+//	@Param			page	query		string	false	"page number"
+//	@Param			limit	query		string	false	"amount items"
+//	@Success		200		{array}		entity.StockAdjustment
+//	@Failure		404		{object}	entity.Error
+//	@Failure		500		{object}	entity.Error
+//	@Router			/products/{id}/inventory/adjustments [get]
+//	@Security		ApiKeyAuth
+func (h *InventoryHandler) ListStockAdjustments(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	adjustments, err := h.InventoryDB.FindAdjustments(r.Context(), product.ID.String(), page, limit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(adjustments)
+}
+
+// ReserveStock godoc
+//
+//	@Summary		Reserve stock
+//	@Description	Hold units of products for a checkout until the reservation is committed, released or expires. Every item is reserved or none is
+//	@Tags			inventory
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.ReserveStockInput	true	"reservation request"
+//	@Success		201		{object}	entity.StockReservation
+//	@Failure		400		{object}	entity.Error
+//	@Failure		409		{object}	entity.Error
+//	@Failure		500		{object}	entity.Error
+//	@Router			/reservations [post]
+//	@Security		ApiKeyAuth
+func (h *InventoryHandler) ReserveStock(w http.ResponseWriter, r *http.Request) {
+	var reservationDTO dto.ReserveStockInput
+	err := json.NewDecoder(r.Body).Decode(&reservationDTO)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	items := make([]entity.StockReservationItem, len(reservationDTO.Items))
+	for i, item := range reservationDTO.Items {
+		product, err := h.ProductDB.FindByID(r.Context(), item.ProductID)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			errorResponse := entity2.Error{Message: fmt.Sprintf("%s: %q", ErrReservedProductNotFound, item.ProductID)}
+			_ = json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+		items[i] = entity.StockReservationItem{ProductID: product.ID, Quantity: item.Quantity}
+	}
+
+	ttl := DefaultReservationTTL
+	if reservationDTO.TTLSeconds != 0 {
+		ttl = time.Duration(reservationDTO.TTLSeconds) * time.Second
+	}
+	reservation, err := entity.NewStockReservation(items, ttl)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = h.InventoryDB.Reserve(r.Context(), reservation)
+	if err != nil {
+		w.WriteHeader(inventoryErrorStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(reservation)
+}
+
+// GetReservation godoc
+//
+//	@Summary		Get a reservation
+//	@Description	Get a stock reservation with its items
+//	@Tags			inventory
+//	@Produce		json
+//	@Param			id	path		string	true	"reservation ID"	Format(uuid)
+//	@Success		200	{object}	entity.StockReservation
+//	@Failure		404	{object}	entity.Error
+//	@Router			/reservations/{id} [get]
+//	@Security		ApiKeyAuth
+func (h *InventoryHandler) GetReservation(w http.ResponseWriter, r *http.Request) {
+	reservation, err := h.InventoryDB.FindReservation(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(reservation)
+}
+
+// CommitReservation godoc
+//
+//	@Summary		Commit a reservation
+//	@Description	Take the reserved units out of stock once the checkout is paid
+//	@Tags			inventory
+//	@Produce		json
+//	@Param			id	path		string	true	"reservation ID"	Format(uuid)
+//	@Success		200	{object}	entity.StockReservation
+//	@Failure		404	{object}	entity.Error
+//	@Failure		409	{object}	entity.Error
+//	@Failure		410	{object}	entity.Error
+//	@Failure		500	{object}	entity.Error
+//	@Router			/reservations/{id}/commit [post]
+//	@Security		ApiKeyAuth
+func (h *InventoryHandler) CommitReservation(w http.ResponseWriter, r *http.Request) {
+	userID, _ := currentUser(r)
+	reservation, err := h.InventoryDB.CommitReservation(r.Context(), chi.URLParam(r, "id"), userID)
+	if err != nil {
+		w.WriteHeader(inventoryErrorStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(reservation)
+}
+
+// ReleaseReservation godoc
+//
+//	@Summary		Release a reservation
+//	@Description	Give the reserved units back to the stock when the checkout is abandoned
+//	@Tags			inventory
+//	@Produce		json
+//	@Param			id	path		string	true	"reservation ID"	Format(uuid)
+//	@Success		200	{object}	entity.StockReservation
+//	@Failure		404	{object}	entity.Error
+//	@Failure		409	{object}	entity.Error
+//	@Failure		500	{object}	entity.Error
+//	@Router			/reservations/{id}/release [post]
+//	@Security		ApiKeyAuth
+func (h *InventoryHandler) ReleaseReservation(w http.ResponseWriter, r *http.Request) {
+	reservation, err := h.InventoryDB.ReleaseReservation(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(inventoryErrorStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(reservation)
+}
+
+func inventoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, database.ErrInsufficientStock), errors.Is(err, database.ErrReservationNotPending):
+		return http.StatusConflict
+	case errors.Is(err, database.ErrReservationExpired):
+		return http.StatusGone
+	}
+	return http.StatusInternalServerError
+}
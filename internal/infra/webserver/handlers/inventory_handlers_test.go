@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"net/http"
+	"testing"
+)
+
+func TestInventoryErrorStatus(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, inventoryErrorStatus(gorm.ErrRecordNotFound))
+	assert.Equal(t, http.StatusConflict, inventoryErrorStatus(fmt.Errorf("%w: product 1", database.ErrInsufficientStock)))
+	assert.Equal(t, http.StatusConflict, inventoryErrorStatus(database.ErrReservationNotPending))
+	assert.Equal(t, http.StatusGone, inventoryErrorStatus(database.ErrReservationExpired))
+	assert.Equal(t, http.StatusInternalServerError, inventoryErrorStatus(errors.New("boom")))
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/SchunckLeonardo/go-expert-api/internal/dto"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+type PriceHandler struct {
+	PriceDB   database.PriceInterface
+	ProductDB database.ProductInterface
+}
+
+func NewPriceHandler(db database.PriceInterface, productDB database.ProductInterface) *PriceHandler {
+	return &PriceHandler{PriceDB: db, ProductDB: productDB}
+}
+
+// ListPriceHistory godoc
+//
+//	@Summary		List the price history of a product
+//	@Description	List the prices a product was given, by whom and when, the latest first
+//	@Tags			prices
+//	@Produce		json
+//	@Param			id		path		string	true	"product ID"	Format(uuid)
+//	@Param			page	query		string	false	"page number"
+//	@Param			limit	query		string	false	"amount items"
+//	@Success		200		{array}		entity.PriceChange
+//	@Failure		404		{object}	entity.Error
+//	@Failure		500		{object}	entity.Error
+//	@Router			/products/{id}/prices [get]
+//	@Security		ApiKeyAuth
+func (h *PriceHandler) ListPriceHistory(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	changes, err := h.PriceDB.FindHistory(r.Context(), product.ID.String(), page, limit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(changes)
+}
+
+// ListScheduledPrices godoc
+//
+//	@Summary		List the scheduled prices of a product
+//	@Description	List the scheduled prices of a product in any status, the soonest first
+//	@Tags			prices
+//	@Produce		json
+//	@Param			id	path		string	true	"product ID"	Format(uuid)
+//	@Success		200	{array}		entity.ScheduledPrice
+//	@Failure		404	{object}	entity.Error
+//	@Failure		500	{object}	entity.Error
+//	@Router			/products/{id}/prices/scheduled [get]
+//	@Security		ApiKeyAuth
+func (h *PriceHandler) ListScheduledPrices(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	scheduled, err := h.PriceDB.FindScheduled(r.Context(), product.ID.String())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(scheduled)
+}
+
+// SchedulePrice godoc
+//
+//	@Summary		Schedule a price
+//	@Description	Schedule a price the product gets at starts_at. With ends_at it is a promotion, after which the product gets back the price it had, unless its price was changed meanwhile. Scheduled prices of a product can't overlap
+//	@Tags			prices
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string					true	"product ID"	Format(uuid)
+//	@Param			request	body		dto.SchedulePriceInput	true	"scheduled price request"
+//	@Success		201		{object}	entity.ScheduledPrice
+//	@Failure		400		{object}	entity.Error
+//	@Failure		403		{object}	entity.Error
+//	@Failure		404		{object}	entity.Error
+//	@Failure		409		{object}	entity.Error
+//	@Failure		500		{object}	entity.Error
+//	@Router			/products/{id}/prices/scheduled [post]
+//	@Security		ApiKeyAuth
+func (h *PriceHandler) SchedulePrice(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if !canModify(r, product) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := entity2.Error{Message: ErrNotProductOwner.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var scheduleDTO dto.SchedulePriceInput
+	err = json.NewDecoder(r.Body).Decode(&scheduleDTO)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	userID, _ := currentUser(r)
+	scheduled, err := entity.NewScheduledPrice(product.ID, scheduleDTO.Price, scheduleDTO.StartsAt, scheduleDTO.EndsAt, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = h.PriceDB.Schedule(r.Context(), scheduled)
+	if err != nil {
+		w.WriteHeader(priceErrorStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(scheduled)
+}
+
+// CancelScheduledPrice godoc
+//
+//	@Summary		Cancel a scheduled price
+//	@Description	Cancel a scheduled price that didn't start yet
+//	@Tags			prices
+//	@Produce		json
+//	@Param			id			path		string	true	"product ID"			Format(uuid)
+//	@Param			schedule_id	path		string	true	"scheduled price ID"	Format(uuid)
+//	@Success		200			{object}	entity.ScheduledPrice
+//	@Failure		403			{object}	entity.Error
+//	@Failure		404			{object}	entity.Error
+//	@Failure		409			{object}	entity.Error
+//	@Failure		500			{object}	entity.Error
+//	@Router			/products/{id}/prices/scheduled/{schedule_id}/cancel [post]
+//	@Security		ApiKeyAuth
+func (h *PriceHandler) CancelScheduledPrice(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if !canModify(r, product) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := entity2.Error{Message: ErrNotProductOwner.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	scheduled, err := h.PriceDB.CancelScheduled(r.Context(), product.ID.String(), chi.URLParam(r, "schedule_id"))
+	if err != nil {
+		w.WriteHeader(priceErrorStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(scheduled)
+}
+
+func priceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, database.ErrScheduleOverlap), errors.Is(err, database.ErrScheduleNotPending):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPriceErrorStatus(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, priceErrorStatus(gorm.ErrRecordNotFound))
+	assert.Equal(t, http.StatusConflict, priceErrorStatus(database.ErrScheduleOverlap))
+	assert.Equal(t, http.StatusConflict, priceErrorStatus(database.ErrScheduleNotPending))
+	assert.Equal(t, http.StatusInternalServerError, priceErrorStatus(errors.New("boom")))
+}
+
+func TestPriceRoutes(t *testing.T) {
+	db := utils.OpenDBConnection(t)
+	h := NewPriceHandler(database.NewPrice(db), database.NewProduct(db))
+	router := newTestRouter()
+	router.Post("/products/{id}/prices/scheduled", h.SchedulePrice)
+	router.Post("/products/{id}/prices/scheduled/{schedule_id}/cancel", h.CancelScheduledPrice)
+
+	owner, other, admin := entity2.NewID(), entity2.NewID(), entity2.NewID()
+	laptop := createOwnedProduct(t, db, "Laptop", owner)
+	mouse := createOwnedProduct(t, db, "Mouse", other)
+	laptopScheduled := "/products/" + laptop.ID.String() + "/prices/scheduled"
+	mouseScheduled := "/products/" + mouse.ID.String() + "/prices/scheduled"
+
+	startsAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	endsAt := startsAt.Add(24 * time.Hour)
+	input := map[string]interface{}{"price": usd(8000), "starts_at": startsAt, "ends_at": endsAt}
+
+	w := serveAs(t, router, other, entity.RoleEditor, http.MethodPost, laptopScheduled, input)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, ErrNotProductOwner.Error(), decodeError(t, w))
+
+	w = serveAs(t, router, owner, entity.RoleEditor, http.MethodPost, laptopScheduled, input)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var scheduled entity.ScheduledPrice
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&scheduled))
+	assert.Equal(t, laptop.ID, scheduled.ProductID)
+	assert.Equal(t, usd(8000), scheduled.Price)
+	assert.True(t, startsAt.Equal(scheduled.StartsAt))
+	assert.Equal(t, entity.ScheduledPricePending, scheduled.Status)
+	assert.Equal(t, owner.String(), scheduled.CreatedBy)
+
+	overlapping := map[string]interface{}{"price": usd(7000), "starts_at": startsAt.Add(time.Hour)}
+	w = serveAs(t, router, admin, entity.RoleAdmin, http.MethodPost, laptopScheduled, overlapping)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, database.ErrScheduleOverlap.Error(), decodeError(t, w))
+
+	cancel := laptopScheduled + "/" + scheduled.ID.String() + "/cancel"
+	w = serveAs(t, router, other, entity.RoleEditor, http.MethodPost, cancel, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, ErrNotProductOwner.Error(), decodeError(t, w))
+
+	w = serveAs(t, router, other, entity.RoleEditor, http.MethodPost, mouseScheduled+"/"+scheduled.ID.String()+"/cancel", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serveAs(t, router, owner, entity.RoleEditor, http.MethodPost, cancel, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&scheduled))
+	assert.Equal(t, entity.ScheduledPriceCanceled, scheduled.Status)
+
+	w = serveAs(t, router, owner, entity.RoleEditor, http.MethodPost, cancel, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, database.ErrScheduleNotPending.Error(), decodeError(t, w))
+}
@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/internal/dto"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+const (
+	// BulkModeAtomic applies every item of a bulk request in a single
+	// transaction, or none of them when one fails.
+	BulkModeAtomic = "atomic"
+	// BulkModePerItem applies every valid item on its own and reports each result.
+	BulkModePerItem = "per_item"
+
+	MaxBulkItems = 1000
+	// maxBulkItemSize is the room given to each item in the body of a bulk
+	// request, in bytes, so that larger bodies are rejected before being decoded
+	maxBulkItemSize = 16 << 10
+)
+
+var (
+	ErrInvalidBulkMode    = errors.New("mode must be atomic or per_item")
+	ErrEmptyBulk          = errors.New("at least one item is required")
+	ErrTooManyBulkItems   = fmt.Errorf("at most %d items are accepted", MaxBulkItems)
+	ErrVersionRequired    = errors.New("version is required")
+	ErrBulkItemNotApplied = errors.New("not applied because another item failed")
+)
+
+// BulkCreateProducts godoc
+//
+//	@Summary		Create many products
+//	@Description	Create up to 1000 products, in a single transaction (atomic) or one by one (per_item). Atomic requests answer with the status of the first failed item, per_item ones with 207
+//	@Tags			products
+//	@Accept			json
+//	@Produce		json
+//	@Param			mode	query		string					false	"atomic by default"	Enums(atomic, per_item)
+//	@Param			request	body		[]dto.CreateProductInput	true	"products request"
+//	@Success		201		{object}	dto.BulkOutput
+//	@Success		207		{object}	dto.BulkOutput
+//	@Failure		400		{object}	dto.BulkOutput
+//	@Failure		413		{object}	entity.Error
+//	@Failure		500		{object}	dto.BulkOutput
+//	@Router			/products/bulk [post]
+//	@Security		ApiKeyAuth
+func (h *ProductHandler) BulkCreateProducts(w http.ResponseWriter, r *http.Request) {
+	var inputs []dto.CreateProductInput
+	mode, ok := decodeBulkRequest(w, r, &inputs)
+	if !ok {
+		return
+	}
+
+	userID, _ := currentUser(r)
+	ownerID, err := entity2.ParseID(userID)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	report := newBulkReport(len(inputs))
+	products := make([]*entity.Product, len(inputs))
+	for i, input := range inputs {
+		product, err := entity.NewProduct(input.Name, input.Description, input.Price)
+		if err == nil {
+			err = product.SetTags(input.Tags)
+		}
+		if err != nil {
+			report.fail(i, http.StatusBadRequest, err)
+			continue
+		}
+		product.OwnerID = ownerID
+		products[i] = product
+	}
+
+	applyBulk(r.Context(), w, mode, report, products, http.StatusCreated, h.ProductDB.Create, h.ProductDB.CreateBatch)
+}
+
+// BulkUpdateProducts godoc
+//
+//	@Summary		Update many products
+//	@Description	Update up to 1000 products with the same rules as PUT /products/{id}, where each item carries the version it expects instead of If-Match
+//	@Tags			products
+//	@Accept			json
+//	@Produce		json
+//	@Param			mode	query		string						false	"atomic by default"	Enums(atomic, per_item)
+//	@Param			request	body		[]dto.BulkUpdateProductInput	true	"products request"
+//	@Success		200		{object}	dto.BulkOutput
+//	@Success		207		{object}	dto.BulkOutput
+//	@Failure		400		{object}	dto.BulkOutput
+//	@Failure		403		{object}	dto.BulkOutput
+//	@Failure		404		{object}	dto.BulkOutput
+//	@Failure		412		{object}	dto.BulkOutput
+//	@Failure		413		{object}	entity.Error
+//	@Failure		428		{object}	dto.BulkOutput
+//	@Failure		500		{object}	dto.BulkOutput
+//	@Router			/products/bulk [put]
+//	@Security		ApiKeyAuth
+func (h *ProductHandler) BulkUpdateProducts(w http.ResponseWriter, r *http.Request) {
+	var inputs []dto.BulkUpdateProductInput
+	mode, ok := decodeBulkRequest(w, r, &inputs)
+	if !ok {
+		return
+	}
+
+	report := newBulkReport(len(inputs))
+	products := make([]*entity.Product, len(inputs))
+	for i, input := range inputs {
+		report.results[i].ID = input.ID
+		product, status, err := h.findForBulk(r, input.ID, input.Version)
+		if err != nil {
+			report.fail(i, status, err)
+			continue
+		}
+		if err = applyUpdateInput(product, input.UpdateProductInput); err != nil {
+			report.fail(i, http.StatusBadRequest, err)
+			continue
+		}
+		products[i] = product
+	}
+
+	applyBulk(r.Context(), w, mode, report, products, http.StatusOK, h.ProductDB.Update, h.ProductDB.UpdateBatch)
+}
+
+// BulkDeleteProducts godoc
+//
+//	@Summary		Delete many products
+//	@Description	Delete up to 1000 products, where each item carries the version it expects instead of If-Match
+//	@Tags			products
+//	@Accept			json
+//	@Produce		json
+//	@Param			mode	query		string						false	"atomic by default"	Enums(atomic, per_item)
+//	@Param			request	body		[]dto.BulkDeleteProductInput	true	"products request"
+//	@Success		200		{object}	dto.BulkOutput
+//	@Success		207		{object}	dto.BulkOutput
+//	@Failure		400		{object}	dto.BulkOutput
+//	@Failure		403		{object}	dto.BulkOutput
+//	@Failure		404		{object}	dto.BulkOutput
+//	@Failure		412		{object}	dto.BulkOutput
+//	@Failure		413		{object}	entity.Error
+//	@Failure		428		{object}	dto.BulkOutput
+//	@Failure		500		{object}	dto.BulkOutput
+//	@Router			/products/bulk [delete]
+//	@Security		ApiKeyAuth
+func (h *ProductHandler) BulkDeleteProducts(w http.ResponseWriter, r *http.Request) {
+	var inputs []dto.BulkDeleteProductInput
+	mode, ok := decodeBulkRequest(w, r, &inputs)
+	if !ok {
+		return
+	}
+
+	report := newBulkReport(len(inputs))
+	products := make([]*entity.Product, len(inputs))
+	for i, input := range inputs {
+		report.results[i].ID = input.ID
+		product, status, err := h.findForBulk(r, input.ID, input.Version)
+		if err != nil {
+			report.fail(i, status, err)
+			continue
+		}
+		products[i] = product
+	}
+
+	deleteProduct := func(ctx context.Context, product *entity.Product) error {
+		return h.ProductDB.DeleteVersion(ctx, product.ID.String(), product.Version)
+	}
+	applyBulk(r.Context(), w, mode, report, products, http.StatusOK, deleteProduct, h.ProductDB.DeleteBatch)
+}
+
+// findForBulk loads the product of a bulk item and checks that the current
+// user can change it at version. It returns the status of the failure otherwise.
+func (h *ProductHandler) findForBulk(r *http.Request, id string, version int) (*entity.Product, int, error) {
+	product, err := h.ProductDB.FindByID(r.Context(), id)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	if !canModify(r, product) {
+		return nil, http.StatusForbidden, ErrNotProductOwner
+	}
+	if version == 0 {
+		return nil, http.StatusPreconditionRequired, ErrVersionRequired
+	}
+	if version != product.Version {
+		return nil, http.StatusPreconditionFailed, ErrVersionMismatch
+	}
+	return product, 0, nil
+}
+
+// decodeBulkRequest reads the mode and the items of a bulk request into
+// inputs, writing the error and returning false when they are invalid.
+func decodeBulkRequest[T any](w http.ResponseWriter, r *http.Request, inputs *[]T) (string, bool) {
+	status := http.StatusBadRequest
+	mode, err := bulkMode(r)
+	if err == nil {
+		r.Body = http.MaxBytesReader(w, r.Body, MaxBulkItems*maxBulkItemSize)
+		err = json.NewDecoder(r.Body).Decode(inputs)
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		status = http.StatusRequestEntityTooLarge
+	}
+	if err == nil && len(*inputs) == 0 {
+		err = ErrEmptyBulk
+	}
+	if err == nil && len(*inputs) > MaxBulkItems {
+		status, err = http.StatusRequestEntityTooLarge, ErrTooManyBulkItems
+	}
+	if err == nil {
+		return mode, true
+	}
+
+	w.WriteHeader(status)
+	errorResponse := entity2.Error{Message: err.Error()}
+	_ = json.NewEncoder(w).Encode(errorResponse)
+	return "", false
+}
+
+// bulkMode returns the mode query parameter of r, atomic by default.
+func bulkMode(r *http.Request) (string, error) {
+	mode := r.URL.Query().Get("mode")
+	switch mode {
+	case "":
+		return BulkModeAtomic, nil
+	case BulkModeAtomic, BulkModePerItem:
+		return mode, nil
+	default:
+		return "", ErrInvalidBulkMode
+	}
+}
+
+// applyBulk stores the products that passed validation, where a nil product
+// is an item that already failed in report, and writes the report.
+func applyBulk(
+	ctx context.Context,
+	w http.ResponseWriter,
+	mode string,
+	report *bulkReport,
+	products []*entity.Product,
+	status int,
+	apply func(context.Context, *entity.Product) error,
+	applyBatch func(context.Context, []*entity.Product) error,
+) {
+	if mode == BulkModePerItem {
+		for i, product := range products {
+			if product == nil {
+				continue
+			}
+			if err := apply(ctx, product); err != nil {
+				report.fail(i, bulkErrorStatus(err), err)
+				continue
+			}
+			report.succeed(i, status, product)
+		}
+		report.write(w, http.StatusMultiStatus)
+		return
+	}
+
+	if !report.failed() {
+		err := applyBatch(ctx, products)
+		var batchErr *database.BatchError
+		switch {
+		case errors.As(err, &batchErr):
+			report.fail(batchErr.Index, bulkErrorStatus(batchErr.Err), batchErr.Err)
+		case err != nil:
+			for i := range products {
+				report.fail(i, bulkErrorStatus(err), err)
+			}
+		default:
+			for i, product := range products {
+				report.succeed(i, status, product)
+			}
+			report.write(w, status)
+			return
+		}
+	}
+
+	status = report.firstFailure()
+	report.abort()
+	report.write(w, status)
+}
+
+func bulkErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, database.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// bulkReport holds the result of every item of a bulk request, in request order.
+type bulkReport struct {
+	results []dto.BulkItemResult
+}
+
+func newBulkReport(size int) *bulkReport {
+	report := &bulkReport{results: make([]dto.BulkItemResult, size)}
+	for i := range report.results {
+		report.results[i].Index = i
+	}
+	return report
+}
+
+func (b *bulkReport) succeed(i, status int, product *entity.Product) {
+	b.results[i].ID = product.ID.String()
+	b.results[i].Version = product.Version
+	b.results[i].Status = status
+}
+
+func (b *bulkReport) fail(i, status int, err error) {
+	if errors.Is(err, database.ErrVersionConflict) {
+		err = ErrVersionMismatch
+	}
+	b.results[i].Status = status
+	b.results[i].Error = err.Error()
+}
+
+func (b *bulkReport) failed() bool {
+	return b.firstFailure() != 0
+}
+
+// firstFailure returns the status of the first failed item, or 0 when none failed.
+func (b *bulkReport) firstFailure() int {
+	for _, result := range b.results {
+		if result.Error != "" {
+			return result.Status
+		}
+	}
+	return 0
+}
+
+// abort marks every item that didn't fail as not applied, after a failed atomic request.
+func (b *bulkReport) abort() {
+	for i := range b.results {
+		if b.results[i].Error == "" {
+			b.results[i].Version = 0
+			b.results[i].Status = http.StatusFailedDependency
+			b.results[i].Error = ErrBulkItemNotApplied.Error()
+		}
+	}
+}
+
+func (b *bulkReport) write(w http.ResponseWriter, status int) {
+	output := dto.BulkOutput{Results: b.results}
+	for _, result := range b.results {
+		if result.Error == "" {
+			output.Succeeded++
+		} else {
+			output.Failed++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(output)
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/SchunckLeonardo/go-expert-api/internal/dto"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func bulkProducts(t *testing.T) []*entity.Product {
+	first, err := entity.NewProduct("Laptop", "", usd(100000))
+	assert.Nil(t, err)
+	second, err := entity.NewProduct("Mouse", "", usd(5000))
+	assert.Nil(t, err)
+	return []*entity.Product{first, second}
+}
+
+func decodeBulkOutput(t *testing.T, w *httptest.ResponseRecorder) dto.BulkOutput {
+	var output dto.BulkOutput
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&output))
+	return output
+}
+
+func TestApplyBulk_Atomic(t *testing.T) {
+	products := bulkProducts(t)
+	batchFails := func(context.Context, []*entity.Product) error {
+		return &database.BatchError{Index: 1, Err: database.ErrVersionConflict}
+	}
+
+	w := httptest.NewRecorder()
+	applyBulk(context.Background(), w, BulkModeAtomic, newBulkReport(2), products, http.StatusOK, nil, batchFails)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	output := decodeBulkOutput(t, w)
+	assert.Equal(t, 0, output.Succeeded)
+	assert.Equal(t, 2, output.Failed)
+	assert.Equal(t, http.StatusFailedDependency, output.Results[0].Status)
+	assert.Equal(t, http.StatusPreconditionFailed, output.Results[1].Status)
+	assert.Equal(t, ErrVersionMismatch.Error(), output.Results[1].Error)
+
+	w = httptest.NewRecorder()
+	applyBulk(context.Background(), w, BulkModeAtomic, newBulkReport(2), products, http.StatusCreated, nil, func(context.Context, []*entity.Product) error { return nil })
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	output = decodeBulkOutput(t, w)
+	assert.Equal(t, 2, output.Succeeded)
+	assert.Equal(t, products[1].ID.String(), output.Results[1].ID)
+}
+
+func TestApplyBulk_AtomicSkipsBatchAfterValidationError(t *testing.T) {
+	products := bulkProducts(t)
+	report := newBulkReport(3)
+	report.fail(2, http.StatusBadRequest, entity.ErrNameIsRequired)
+
+	w := httptest.NewRecorder()
+	applyBulk(context.Background(), w, BulkModeAtomic, report, append(products, nil), http.StatusCreated, nil, func(context.Context, []*entity.Product) error {
+		t.Fatal("batch must not run")
+		return nil
+	})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	output := decodeBulkOutput(t, w)
+	assert.Equal(t, 3, output.Failed)
+	assert.Equal(t, http.StatusFailedDependency, output.Results[0].Status)
+}
+
+func TestApplyBulk_PerItem(t *testing.T) {
+	products := bulkProducts(t)
+	report := newBulkReport(3)
+	report.fail(1, http.StatusBadRequest, entity.ErrNameIsRequired)
+
+	apply := func(_ context.Context, product *entity.Product) error {
+		if product == products[1] {
+			return errors.New("boom")
+		}
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	applyBulk(context.Background(), w, BulkModePerItem, report, []*entity.Product{products[0], nil, products[1]}, http.StatusCreated, apply, nil)
+
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	output := decodeBulkOutput(t, w)
+	assert.Equal(t, 1, output.Succeeded)
+	assert.Equal(t, 2, output.Failed)
+	assert.Equal(t, http.StatusCreated, output.Results[0].Status)
+	assert.Equal(t, http.StatusBadRequest, output.Results[1].Status)
+	assert.Equal(t, http.StatusInternalServerError, output.Results[2].Status)
+}
+
+func TestDecodeBulkRequest_Limits(t *testing.T) {
+	var inputs []dto.BulkDeleteProductInput
+	tooLarge := `[{"id":"` + strings.Repeat("a", MaxBulkItems*maxBulkItemSize) + `"}]`
+	r := httptest.NewRequest(http.MethodDelete, "/products/bulk", strings.NewReader(tooLarge))
+	w := httptest.NewRecorder()
+	_, ok := decodeBulkRequest(w, r, &inputs)
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	tooMany := "[" + strings.Repeat(`{"id":"1","version":1},`, MaxBulkItems) + `{"id":"1","version":1}]`
+	r = httptest.NewRequest(http.MethodDelete, "/products/bulk", strings.NewReader(tooMany))
+	w = httptest.NewRecorder()
+	_, ok = decodeBulkRequest(w, r, &inputs)
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	r = httptest.NewRequest(http.MethodDelete, "/products/bulk?mode=per_item", strings.NewReader(`[{"id":"1","version":1}]`))
+	mode, ok := decodeBulkRequest(httptest.NewRecorder(), r, &inputs)
+	assert.True(t, ok)
+	assert.Equal(t, BulkModePerItem, mode)
+	assert.Len(t, inputs, 1)
+}
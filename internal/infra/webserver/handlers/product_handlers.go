@@ -2,22 +2,42 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/SchunckLeonardo/go-expert-api/internal/dto"
 	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
 	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
 	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
 	"github.com/go-chi/chi/v5"
+	"io"
 	"math"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxFetchLimit is the most products FetchProducts returns in a page.
+const MaxFetchLimit = 100
+
+var (
+	ErrInvalidPage       = errors.New("page must be 1 or greater")
+	ErrNotProductOwner   = errors.New("only the owner or an admin can change this product")
+	ErrInvalidPriceRange = errors.New("min_price can't be greater than max_price")
+	ErrInvalidDateRange  = errors.New("created_from can't be after created_to")
+	ErrProductNotDeleted = errors.New("product is not deleted")
+	ErrOnlyAdminDeleted  = errors.New("only admins can list deleted products")
+	ErrInvalidTagsMatch  = errors.New("tags_match must be any or all")
 )
 
 type ProductHandler struct {
-	ProductDB database.ProductInterface
+	ProductDB      database.ProductInterface
+	ExchangeRateDB database.ExchangeRateInterface
 }
 
-func NewProductHandler(db database.ProductInterface) *ProductHandler {
-	return &ProductHandler{ProductDB: db}
+func NewProductHandler(db database.ProductInterface, exchangeRateDB database.ExchangeRateInterface) *ProductHandler {
+	return &ProductHandler{ProductDB: db, ExchangeRateDB: exchangeRateDB}
 }
 
 // CreateProduct godoc
@@ -44,6 +64,9 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	product, err := entity.NewProduct(productDTO.Name, productDTO.Description, productDTO.Price)
+	if err == nil {
+		err = product.SetTags(productDTO.Tags)
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		errorResponse := entity2.Error{Message: err.Error()}
@@ -51,7 +74,16 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.ProductDB.Create(product)
+	userID, _ := currentUser(r)
+	product.OwnerID, err = entity2.ParseID(userID)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = h.ProductDB.Create(r.Context(), product)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		errorResponse := entity2.Error{Message: err.Error()}
@@ -69,11 +101,13 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 //	@Tags			products
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		string	true	"product ID"	Format(uuid)
-//	@Success		200	{object}	entity.Product
-//	@Failure		400	{object}	entity.Error
-//	@Failure		404	{object}	entity.Error
-//	@Failure		500	{object}	entity.Error
+//	@Param			id			path		string	true	"product ID"	Format(uuid)
+//	@Param			currency	query		string	false	"currency to convert the price to with the exchange rates"
+//	@Success		200			{object}	entity.Product
+//	@Header			200			{string}	ETag	"product version, to send as If-Match"
+//	@Failure		400			{object}	entity.Error
+//	@Failure		404			{object}	entity.Error
+//	@Failure		500			{object}	entity.Error
 //	@Router			/products/{id} [get]
 //	@Security		ApiKeyAuth
 func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
@@ -82,7 +116,7 @@ func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	product, err := h.ProductDB.FindByID(id)
+	product, err := h.ProductDB.FindByID(r.Context(), id)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		errorResponse := entity2.Error{Message: err.Error()}
@@ -90,6 +124,14 @@ func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = h.convertPrices(r, []*entity.Product{product}); err != nil {
+		w.WriteHeader(priceConversionStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(product.Version))
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(product)
 }
@@ -101,11 +143,16 @@ func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 //	@Tags			products
 //	@Accept			json
 //	@Produce		json
-//	@Param			id		path	string					true	"product ID"	Format(uuid)
-//	@Param			request	body	dto.UpdateProductInput	true	"Product fields that can be changed"
+//	@Param			id			path	string					true	"product ID"	Format(uuid)
+//	@Param			If-Match	header	string					true	"ETag of the product being updated, or *"
+//	@Param			request		body	dto.UpdateProductInput	true	"Product fields that can be changed"
 //	@Success		200
+//	@Header			200	{string}	ETag	"new product version"
 //	@Failure		400	{object}	entity.Error
+//	@Failure		403	{object}	entity.Error
 //	@Failure		404	{object}	entity.Error
+//	@Failure		412	{object}	entity.Error
+//	@Failure		428	{object}	entity.Error
 //	@Failure		500	{object}	entity.Error
 //	@Router			/products/{id} [put]
 //	@Security		ApiKeyAuth
@@ -116,7 +163,7 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	product, err := h.ProductDB.FindByID(id)
+	product, err := h.ProductDB.FindByID(r.Context(), id)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		errorResponse := entity2.Error{Message: err.Error()}
@@ -124,6 +171,17 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !canModify(r, product) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := entity2.Error{Message: ErrNotProductOwner.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if !checkIfMatch(w, r, product.Version) {
+		return
+	}
+
 	var productDTO dto.UpdateProductInput
 	err = json.NewDecoder(r.Body).Decode(&productDTO)
 	if err != nil {
@@ -133,19 +191,144 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if productDTO.Name != "" {
-		product.Name = productDTO.Name
+	err = applyUpdateInput(product, productDTO)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = h.ProductDB.Update(r.Context(), product)
+	if errors.Is(err, database.ErrVersionConflict) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		errorResponse := entity2.Error{Message: ErrVersionMismatch.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(product.Version))
+	w.WriteHeader(http.StatusOK)
+}
+
+// applyUpdateInput changes the fields of product that were sent in input,
+// where empty names and descriptions or prices that aren't positive count as not sent.
+func applyUpdateInput(product *entity.Product, input dto.UpdateProductInput) error {
+	if input.Name != "" {
+		product.Name = input.Name
+	}
+
+	if input.Description != "" {
+		product.Description = input.Description
+	}
+
+	if input.Price.Amount > 0 {
+		product.Price = input.Price
+	}
+
+	if input.Tags != nil {
+		return product.SetTags(input.Tags)
+	}
+	return nil
+}
+
+// PatchProduct godoc
+//
+//	@Summary		Patch a product
+//	@Description	Change some fields of a product with a JSON merge patch (RFC 7396), where null clears a field, or a JSON patch (RFC 6902) on /name, /description, /price and /tags
+//	@Tags			products
+//	@Accept			application/merge-patch+json,application/json-patch+json,json
+//	@Produce		json
+//	@Param			id			path		string	true	"product ID"	Format(uuid)
+//	@Param			If-Match	header		string	true	"ETag of the product being patched, or *"
+//	@Param			request		body		object	true	"merge patch object or JSON patch operations"
+//	@Success		200			{object}	entity.Product
+//	@Header			200			{string}	ETag	"new product version"
+//	@Failure		400			{object}	entity.Error
+//	@Failure		403			{object}	entity.Error
+//	@Failure		404			{object}	entity.Error
+//	@Failure		409			{object}	entity.Error
+//	@Failure		412			{object}	entity.Error
+//	@Failure		415			{object}	entity.Error
+//	@Failure		428			{object}	entity.Error
+//	@Failure		500			{object}	entity.Error
+//	@Router			/products/{id} [patch]
+//	@Security		ApiKeyAuth
+func (h *ProductHandler) PatchProduct(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var applyPatch func(*entity.Product, []byte) error
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case MergePatchContentType, "application/json":
+		applyPatch = applyMergePatch
+	case JSONPatchContentType:
+		applyPatch = applyJSONPatch
+	default:
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		errorResponse := entity2.Error{Message: "use " + MergePatchContentType + " or " + JSONPatchContentType}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	product, err := h.ProductDB.FindByID(r.Context(), id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if !canModify(r, product) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := entity2.Error{Message: ErrNotProductOwner.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
 	}
 
-	if productDTO.Description != "" {
-		product.Description = productDTO.Description
+	if !checkIfMatch(w, r, product.Version) {
+		return
 	}
 
-	if productDTO.Price >= 1.0 {
-		product.Price = productDTO.Price
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
 	}
 
-	err = h.ProductDB.Update(product)
+	err = applyPatch(product, patch)
+	if errors.Is(err, ErrPatchTestFailed) {
+		w.WriteHeader(http.StatusConflict)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = h.ProductDB.Update(r.Context(), product)
+	if errors.Is(err, database.ErrVersionConflict) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		errorResponse := entity2.Error{Message: ErrVersionMismatch.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		errorResponse := entity2.Error{Message: err.Error()}
@@ -153,7 +336,17 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	if err = h.convertPrices(r, []*entity.Product{product}); err != nil {
+		w.WriteHeader(priceConversionStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(product.Version))
 	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(product)
 }
 
 // DeleteProduct godoc
@@ -163,9 +356,14 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 //	@Tags			products
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path	string	true	"product ID"	Format(uuid)
+//	@Param			id			path	string	true	"product ID"	Format(uuid)
+//	@Param			If-Match	header	string	true	"ETag of the product being deleted, or *"
 //	@Success		200
+//	@Failure		400	{object}	entity.Error
+//	@Failure		403	{object}	entity.Error
 //	@Failure		404	{object}	entity.Error
+//	@Failure		412	{object}	entity.Error
+//	@Failure		428	{object}	entity.Error
 //	@Failure		500	{object}	entity.Error
 //	@Router			/products/{id} [delete]
 //	@Security		ApiKeyAuth
@@ -176,7 +374,65 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err := h.ProductDB.FindByID(id)
+	product, err := h.ProductDB.FindByID(r.Context(), id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if !canModify(r, product) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := entity2.Error{Message: ErrNotProductOwner.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if !checkIfMatch(w, r, product.Version) {
+		return
+	}
+
+	err = h.ProductDB.DeleteVersion(r.Context(), id, product.Version)
+	if errors.Is(err, database.ErrVersionConflict) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		errorResponse := entity2.Error{Message: ErrVersionMismatch.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// RestoreProduct godoc
+//
+//	@Summary		Restore a deleted product
+//	@Description	Restore a deleted product that was not purged yet
+//	@Tags			products
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path	string	true	"product ID"	Format(uuid)
+//	@Success		200
+//	@Failure		403	{object}	entity.Error
+//	@Failure		404	{object}	entity.Error
+//	@Failure		409	{object}	entity.Error
+//	@Failure		500	{object}	entity.Error
+//	@Router			/products/{id}/restore [post]
+//	@Security		ApiKeyAuth
+func (h *ProductHandler) RestoreProduct(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.ProductDB.FindByIDWithDeleted(r.Context(), id)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		errorResponse := entity2.Error{Message: err.Error()}
@@ -184,7 +440,21 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.ProductDB.Delete(id)
+	if !canModify(r, product) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := entity2.Error{Message: ErrNotProductOwner.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if !product.IsDeleted() {
+		w.WriteHeader(http.StatusConflict)
+		errorResponse := entity2.Error{Message: ErrProductNotDeleted.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = h.ProductDB.Restore(r.Context(), id)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		errorResponse := entity2.Error{Message: err.Error()}
@@ -202,11 +472,26 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 //	@Tags			products
 //	@Accept			json
 //	@Produce		json
-//	@Param			page	query		string	false	"page number"
-//	@Param			limit	query		string	false	"amount items"
-//	@Param			sort	query		string	false	"sort asc or desc"
-//	@Success		200		{object}	dto.FetchProductsOutput
-//	@Failure		500		{object}	entity.Error
+//	@Param			page			query		string	false	"page number, from 1"
+//	@Param			limit			query		string	false	"amount items, 10 by default and 100 at most"
+//	@Param			sort			query		string	false	"comma separated fields among id, name, description, price and created_at, prefixed by - for descending (e.g. name,-price), where price sorts by currency first. asc or desc sort by created_at"
+//	@Param			mine			query		bool	false	"only products created by the current user"
+//	@Param			q				query		string	false	"text searched in name and description"
+//	@Param			min_price		query		string	false	"minimum price, e.g. 10.50"
+//	@Param			max_price		query		string	false	"maximum price"
+//	@Param			currency		query		string	false	"currency to convert prices to with the exchange rates, also the one of min_price and max_price. USD by default"
+//	@Param			created_from	query		string	false	"created at or after, RFC 3339 or YYYY-MM-DD"
+//	@Param			created_to		query		string	false	"created at or before, RFC 3339 or YYYY-MM-DD"
+//	@Param			category		query		string	false	"category ID, also matches the products of the categories below it"	Format(uuid)
+//	@Param			tags			query		string	false	"comma separated tags"
+//	@Param			tags_match		query		string	false	"products with any of the tags or with all of them, any by default"	Enums(any, all)
+//	@Param			pagination		query		string	false	"cursor to paginate with NextCursor and PrevCursor instead of page"	Enums(cursor)
+//	@Param			cursor			query		string	false	"NextCursor or PrevCursor of a previous response, implies cursor pagination"
+//	@Param			include_deleted	query		bool	false	"also list deleted products, admins only"
+//	@Success		200				{object}	dto.FetchProductsOutput
+//	@Failure		400				{object}	entity.Error
+//	@Failure		403				{object}	entity.Error
+//	@Failure		500				{object}	entity.Error
 //	@Router			/products [get]
 //	@Security		ApiKeyAuth
 func (h *ProductHandler) FetchProducts(w http.ResponseWriter, r *http.Request) {
@@ -214,24 +499,51 @@ func (h *ProductHandler) FetchProducts(w http.ResponseWriter, r *http.Request) {
 	limit := r.URL.Query().Get("limit")
 	sort := r.URL.Query().Get("sort")
 
+	filter, err := h.parseProductFilter(r)
+	if errors.Is(err, ErrOnlyAdminDeleted) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
 	pageInt, err := strconv.Atoi(page)
 	if err != nil {
 		pageInt = 1
 	}
+	if pageInt < 1 {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: ErrInvalidPage.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
 	limitInt, err := strconv.Atoi(limit)
-	if err != nil {
+	if err != nil || limitInt < 1 {
 		limitInt = 10
 	}
-
-	if pageInt == 0 {
-		pageInt = 1
+	if limitInt > MaxFetchLimit {
+		limitInt = MaxFetchLimit
 	}
 
-	if limitInt == 0 {
-		limitInt = 1
+	if r.URL.Query().Get("pagination") == "cursor" || r.URL.Query().Get("cursor") != "" {
+		h.fetchProductsByCursor(w, r, filter, limitInt, sort)
+		return
 	}
 
-	products, err := h.ProductDB.FindAll(pageInt, limitInt, sort)
+	products, err := h.ProductDB.Search(r.Context(), filter, pageInt, limitInt, sort)
+	if errors.Is(err, database.ErrInvalidSortField) {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		errorResponse := entity2.Error{Message: err.Error()}
@@ -239,7 +551,14 @@ func (h *ProductHandler) FetchProducts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	count, err := h.ProductDB.GetProductsCount()
+	if err = h.convertPrices(r, productPointers(products)); err != nil {
+		w.WriteHeader(priceConversionStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	count, err := h.ProductDB.Count(r.Context(), filter)
 
 	var totalPages float64
 	totalPages = float64(count) / float64(limitInt)
@@ -255,3 +574,217 @@ func (h *ProductHandler) FetchProducts(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(response)
 }
+
+// fetchProductsByCursor answers FetchProducts with keyset pagination, which
+// stays consistent when products are inserted between pages and skips counting.
+func (h *ProductHandler) fetchProductsByCursor(w http.ResponseWriter, r *http.Request, filter database.ProductFilter, limit int, sort string) {
+	var cursor *database.ProductCursor
+	var err error
+	if value := r.URL.Query().Get("cursor"); value != "" {
+		cursor, err = database.DecodeProductCursor(value)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			errorResponse := entity2.Error{Message: err.Error()}
+			_ = json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+	}
+
+	products, hasMore, err := h.ProductDB.SearchByCursor(r.Context(), filter, cursor, limit, sort)
+	if errors.Is(err, database.ErrInvalidCursorSort) {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if err = h.convertPrices(r, productPointers(products)); err != nil {
+		w.WriteHeader(priceConversionStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	response := dto.FetchProductsOutput{
+		Products:    products,
+		ItemsAmount: len(products),
+	}
+	if len(products) > 0 {
+		backwards := cursor != nil && cursor.Before
+		// the page we came from always exists, the one we are heading to only when hasMore
+		if cursor != nil && (!backwards || hasMore) {
+			response.PrevCursor = database.CursorBefore(products[0]).Encode()
+		}
+		if backwards || hasMore {
+			response.NextCursor = database.CursorAfter(products[len(products)-1]).Encode()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func (h *ProductHandler) parseProductFilter(r *http.Request) (database.ProductFilter, error) {
+	query := r.URL.Query()
+	filter := database.ProductFilter{Text: strings.TrimSpace(query.Get("q"))}
+	if query.Get("mine") == "true" {
+		filter.OwnerID, _ = currentUser(r)
+	}
+	if query.Get("include_deleted") == "true" {
+		if _, role := currentUser(r); role != entity.RoleAdmin {
+			return filter, ErrOnlyAdminDeleted
+		}
+		filter.IncludeDeleted = true
+	}
+
+	if tags := query.Get("tags"); tags != "" {
+		var err error
+		if filter.Tags, err = entity.NormalizeTags(strings.Split(tags, ",")); err != nil {
+			return filter, fmt.Errorf("invalid tags: %w", err)
+		}
+		switch query.Get("tags_match") {
+		case "", "any":
+		case "all":
+			filter.AllTags = true
+		default:
+			return filter, ErrInvalidTagsMatch
+		}
+	}
+	if category := query.Get("category"); category != "" {
+		if _, err := entity2.ParseID(category); err != nil {
+			return filter, fmt.Errorf("invalid category: %w", err)
+		}
+		filter.CategoryID = category
+	}
+
+	var err error
+	currency := query.Get("currency")
+	if currency == "" {
+		currency = entity.BaseCurrency
+	}
+	minPrice, err := parsePriceParam(query.Get("min_price"), currency)
+	if err != nil {
+		return filter, fmt.Errorf("invalid min_price: %w", err)
+	}
+	maxPrice, err := parsePriceParam(query.Get("max_price"), currency)
+	if err != nil {
+		return filter, fmt.Errorf("invalid max_price: %w", err)
+	}
+	if minPrice != nil && maxPrice != nil && minPrice.Amount > maxPrice.Amount {
+		return filter, ErrInvalidPriceRange
+	}
+	if minPrice != nil || maxPrice != nil {
+		converter, err := h.ExchangeRateDB.Converter(r.Context())
+		if err != nil {
+			return filter, err
+		}
+		// the bounds are widened when converted, so no product is left out by rounding
+		if minPrice != nil {
+			if filter.MinPrices, err = inEveryCurrency(converter, *minPrice, entity2.RoundDown); err != nil {
+				return filter, fmt.Errorf("invalid min_price: %w", err)
+			}
+		}
+		if maxPrice != nil {
+			if filter.MaxPrices, err = inEveryCurrency(converter, *maxPrice, entity2.RoundUp); err != nil {
+				return filter, fmt.Errorf("invalid max_price: %w", err)
+			}
+		}
+	}
+
+	if filter.CreatedFrom, err = parseDateParam(query.Get("created_from"), false); err != nil {
+		return filter, fmt.Errorf("invalid created_from: %w", err)
+	}
+	if filter.CreatedTo, err = parseDateParam(query.Get("created_to"), true); err != nil {
+		return filter, fmt.Errorf("invalid created_to: %w", err)
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && filter.CreatedFrom.After(*filter.CreatedTo) {
+		return filter, ErrInvalidDateRange
+	}
+
+	return filter, nil
+}
+
+func parsePriceParam(value, currency string) (*entity2.Money, error) {
+	if value == "" {
+		return nil, nil
+	}
+	price, err := entity2.ParseMoney(value, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
+
+// inEveryCurrency converts price to every currency known by converter.
+func inEveryCurrency(converter *entity.CurrencyConverter, price entity2.Money, mode entity2.RoundingMode) ([]entity2.Money, error) {
+	var prices []entity2.Money
+	for _, currency := range converter.Currencies() {
+		converted, err := converter.ConvertRounded(price, currency, entity2.Rounding{Mode: mode, Increment: 1})
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, converted)
+	}
+	return prices, nil
+}
+
+// convertPrices converts the price of products to the currency query
+// parameter of r, when there is one.
+func (h *ProductHandler) convertPrices(r *http.Request, products []*entity.Product) error {
+	currency := r.URL.Query().Get("currency")
+	if currency == "" {
+		return nil
+	}
+
+	converter, err := h.ExchangeRateDB.Converter(r.Context())
+	if err != nil {
+		return err
+	}
+	for _, product := range products {
+		if product.Price, err = converter.Convert(product.Price, currency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func priceConversionStatus(err error) int {
+	if errors.Is(err, entity.ErrNoExchangeRate) || errors.Is(err, entity2.ErrInvalidCurrency) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func productPointers(products []entity.Product) []*entity.Product {
+	pointers := make([]*entity.Product, len(products))
+	for i := range products {
+		pointers[i] = &products[i]
+	}
+	return pointers
+}
+
+// parseDateParam accepts RFC 3339 timestamps or plain dates. A plain date used
+// as an upper bound includes the whole day.
+func parseDateParam(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, errors.New("expected RFC 3339 or YYYY-MM-DD")
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return &t, nil
+}
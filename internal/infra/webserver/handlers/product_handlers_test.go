@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/internal/dto"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newProductRouter(db *gorm.DB) *chi.Mux {
+	h := NewProductHandler(database.NewProduct(db), database.NewExchangeRate(db))
+	router := newTestRouter()
+	router.Get("/products/{id}", h.GetProduct)
+	router.Put("/products/{id}", h.UpdateProduct)
+	router.Patch("/products/{id}", h.PatchProduct)
+	router.Delete("/products/{id}", h.DeleteProduct)
+	router.Post("/products/{id}/restore", h.RestoreProduct)
+	return router
+}
+
+// requestIfMatch returns a request from the user userID with role carrying
+// ifMatch as its If-Match header, unless empty.
+func requestIfMatch(t *testing.T, userID entity2.ID, role entity.Role, method, target, ifMatch string, body interface{}) *http.Request {
+	r := requestAs(t, userID, role, method, target, body)
+	if ifMatch != "" {
+		r.Header.Set("If-Match", ifMatch)
+	}
+	return r
+}
+
+func TestProductRoutes_IfMatch(t *testing.T) {
+	db := utils.OpenDBConnection(t)
+	router := newProductRouter(db)
+	owner := entity2.NewID()
+	product := createOwnedProduct(t, db, "Laptop", owner)
+	target := "/products/" + product.ID.String()
+
+	w := serveAs(t, router, owner, entity.RoleViewer, http.MethodGet, target, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+
+	update := map[string]string{"name": "Notebook"}
+	w = serve(router, requestIfMatch(t, owner, entity.RoleEditor, http.MethodPut, target, "", update))
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	assert.Equal(t, ErrIfMatchRequired.Error(), decodeError(t, w))
+
+	w = serve(router, requestIfMatch(t, owner, entity.RoleEditor, http.MethodPut, target, "one", update))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, ErrInvalidIfMatch.Error(), decodeError(t, w))
+
+	w = serve(router, requestIfMatch(t, owner, entity.RoleEditor, http.MethodPut, target, `"2"`, update))
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, ErrVersionMismatch.Error(), decodeError(t, w))
+
+	w = serve(router, requestIfMatch(t, owner, entity.RoleEditor, http.MethodPut, target, `"1"`, update))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	w = serve(router, requestIfMatch(t, owner, entity.RoleEditor, http.MethodPut, target, `W/"1"`, map[string]string{"name": "Laptop"}))
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = serveAs(t, router, owner, entity.RoleViewer, http.MethodGet, target, nil)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	var stored entity.Product
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&stored))
+	assert.Equal(t, "Notebook", stored.Name)
+
+	w = serve(router, requestIfMatch(t, owner, entity.RoleEditor, http.MethodDelete, target, "", nil))
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	assert.Equal(t, ErrIfMatchRequired.Error(), decodeError(t, w))
+
+	w = serve(router, requestIfMatch(t, owner, entity.RoleEditor, http.MethodDelete, target, `"1"`, nil))
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, ErrVersionMismatch.Error(), decodeError(t, w))
+
+	w = serve(router, requestIfMatch(t, owner, entity.RoleEditor, http.MethodDelete, target, `"2"`, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serveAs(t, router, owner, entity.RoleViewer, http.MethodGet, target, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestProductRoutes_Patch(t *testing.T) {
+	db := utils.OpenDBConnection(t)
+	router := newProductRouter(db)
+	owner := entity2.NewID()
+	product := createOwnedProduct(t, db, "Laptop", owner)
+	target := "/products/" + product.ID.String()
+
+	patch := func(contentType, ifMatch string, body interface{}) *httptest.ResponseRecorder {
+		r := requestIfMatch(t, owner, entity.RoleEditor, http.MethodPatch, target, ifMatch, body)
+		r.Header.Set("Content-Type", contentType)
+		return serve(router, r)
+	}
+
+	w := patch(MergePatchContentType, `"1"`, map[string]interface{}{"price": map[string]string{"currency": "EUR"}, "description": "Macbook"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	var patched entity.Product
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&patched))
+	assert.Equal(t, entity2.Money{Amount: 10000, Currency: "EUR"}, patched.Price)
+	assert.Equal(t, "Macbook", patched.Description)
+	assert.Equal(t, 2, patched.Version)
+
+	operations := []map[string]interface{}{
+		{"op": "test", "path": "/name", "value": "Laptop"},
+		{"op": "replace", "path": "/name", "value": "Notebook"},
+		{"op": "remove", "path": "/description"},
+	}
+	w = patch(JSONPatchContentType, `"2"`, operations)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&patched))
+	assert.Equal(t, "Notebook", patched.Name)
+	assert.Equal(t, "", patched.Description)
+	assert.Equal(t, entity2.Money{Amount: 10000, Currency: "EUR"}, patched.Price)
+
+	w = patch(JSONPatchContentType, `"3"`, operations)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = patch(MergePatchContentType, `"2"`, map[string]string{"name": "Laptop"})
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = patch(MergePatchContentType, "", map[string]string{"name": "Laptop"})
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+
+	w = patch("text/plain", `"3"`, map[string]string{"name": "Laptop"})
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	stored, err := database.NewProduct(db).FindByID(context.Background(), product.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, "Notebook", stored.Name)
+	assert.Equal(t, 3, stored.Version)
+}
+
+func TestProductRoutes_OwnerOrAdmin(t *testing.T) {
+	db := utils.OpenDBConnection(t)
+	router := newProductRouter(db)
+	owner, other, admin := entity2.NewID(), entity2.NewID(), entity2.NewID()
+	product := createOwnedProduct(t, db, "Laptop", owner)
+	target := "/products/" + product.ID.String()
+
+	change := func(userID entity2.ID, role entity.Role, method, target string) *httptest.ResponseRecorder {
+		var body interface{}
+		if method == http.MethodPut || method == http.MethodPatch {
+			body = map[string]string{"name": "Laptop " + string(role)}
+		}
+		r := requestIfMatch(t, userID, role, method, target, "*", body)
+		r.Header.Set("Content-Type", MergePatchContentType)
+		return serve(router, r)
+	}
+
+	for _, method := range []string{http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		w := change(other, entity.RoleEditor, method, target)
+		assert.Equal(t, http.StatusForbidden, w.Code, method)
+		assert.Equal(t, ErrNotProductOwner.Error(), decodeError(t, w), method)
+	}
+	for _, method := range []string{http.MethodPut, http.MethodPatch} {
+		assert.Equal(t, http.StatusOK, change(owner, entity.RoleEditor, method, target).Code, method)
+		assert.Equal(t, http.StatusOK, change(admin, entity.RoleAdmin, method, target).Code, method)
+	}
+
+	assert.Equal(t, http.StatusOK, change(owner, entity.RoleEditor, http.MethodDelete, target).Code)
+	w := change(other, entity.RoleEditor, http.MethodPost, target+"/restore")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, ErrNotProductOwner.Error(), decodeError(t, w))
+	assert.Equal(t, http.StatusOK, change(owner, entity.RoleEditor, http.MethodPost, target+"/restore").Code)
+
+	assert.Equal(t, http.StatusOK, change(admin, entity.RoleAdmin, http.MethodDelete, target).Code)
+	assert.Equal(t, http.StatusOK, change(admin, entity.RoleAdmin, http.MethodPost, target+"/restore").Code)
+
+	stored, err := database.NewProduct(db).FindByID(context.Background(), product.ID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, "Laptop admin", stored.Name)
+	assert.Equal(t, owner, stored.OwnerID)
+}
+
+func TestFetchProducts_PageAndLimit(t *testing.T) {
+	db := utils.OpenDBConnection(t)
+	h := NewProductHandler(database.NewProduct(db), database.NewExchangeRate(db))
+	router := newTestRouter()
+	router.Get("/products", h.FetchProducts)
+
+	user := entity2.NewID()
+	for i := 1; i <= MaxFetchLimit+1; i++ {
+		createOwnedProduct(t, db, fmt.Sprintf("Product %d", i), user)
+	}
+
+	fetch := func(query string) (int, dto.FetchProductsOutput) {
+		w := serveAs(t, router, user, entity.RoleViewer, http.MethodGet, "/products?"+query, nil)
+		var output dto.FetchProductsOutput
+		if w.Code == http.StatusOK {
+			assert.Nil(t, json.NewDecoder(w.Body).Decode(&output))
+		}
+		return w.Code, output
+	}
+
+	for _, query := range []string{"limit=-1", "limit=-2", "limit=0", "pagination=cursor&limit=-1", "pagination=cursor&limit=-2"} {
+		status, output := fetch(query)
+		assert.Equal(t, http.StatusOK, status, query)
+		assert.Equal(t, 10, output.ItemsAmount, query)
+	}
+
+	status, output := fetch("limit=1000")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, MaxFetchLimit, output.ItemsAmount)
+	assert.Equal(t, 2, output.TotalPages)
+
+	for _, query := range []string{"page=0", "page=-1&limit=5"} {
+		status, _ = fetch(query)
+		assert.Equal(t, http.StatusBadRequest, status, query)
+	}
+}
@@ -0,0 +1,391 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/internal/dto"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/logging"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	CSVContentType    = "text/csv"
+	NDJSONContentType = "application/x-ndjson"
+
+	MaxImportRows = 10000
+	// MaxImportSize is the largest import body accepted, in bytes
+	MaxImportSize = 32 << 20
+
+	// exportFlushEvery is how many products are written between flushes, each
+	// of which also extends the write deadline by exportWriteTimeout so big
+	// exports aren't cut by the server WriteTimeout.
+	exportFlushEvery   = 500
+	exportWriteTimeout = 15 * time.Second
+
+	maxNDJSONLine = 1 << 20
+)
+
+var (
+	ErrUnacceptableExport = errors.New("products can be exported as " + CSVContentType + " or " + NDJSONContentType)
+	ErrUnsupportedImport  = errors.New("products can be imported from " + CSVContentType + " or " + NDJSONContentType)
+	ErrMissingCSVColumn   = errors.New("missing CSV column")
+	ErrEmptyImport        = errors.New("there are no products to import")
+	ErrTooManyImportRows  = fmt.Errorf("at most %d products can be imported at once", MaxImportRows)
+)
+
+var productCSVHeader = []string{"id", "name", "description", "price", "currency", "owner_id", "version", "created_at", "deleted_at", "tags"}
+
+// ExportProducts godoc
+//
+//	@Summary		Export products
+//	@Description	Stream every product matching the same filters as GET /products, as CSV or newline delimited JSON depending on Accept. Images are not exported
+//	@Tags			products
+//	@Produce		text/csv,application/x-ndjson
+//	@Param			sort			query		string	false	"same as GET /products"
+//	@Param			mine			query		bool	false	"only products created by the current user"
+//	@Param			q				query		string	false	"text searched in name and description"
+//	@Param			min_price		query		string	false	"minimum price, e.g. 10.50"
+//	@Param			max_price		query		string	false	"maximum price"
+//	@Param			currency		query		string	false	"currency of min_price and max_price, USD by default. Prices are exported as they are stored"
+//	@Param			created_from	query		string	false	"created at or after, RFC 3339 or YYYY-MM-DD"
+//	@Param			created_to		query		string	false	"created at or before, RFC 3339 or YYYY-MM-DD"
+//	@Param			category		query		string	false	"category ID, also matches the products of the categories below it"	Format(uuid)
+//	@Param			tags			query		string	false	"comma separated tags"
+//	@Param			tags_match		query		string	false	"products with any of the tags or with all of them, any by default"	Enums(any, all)
+//	@Param			include_deleted	query		bool	false	"also export deleted products, admins only"
+//	@Success		200				{string}	string	"one product per line"
+//	@Failure		400				{object}	entity.Error
+//	@Failure		403				{object}	entity.Error
+//	@Failure		406				{object}	entity.Error
+//	@Router			/products/export [get]
+//	@Security		ApiKeyAuth
+func (h *ProductHandler) ExportProducts(w http.ResponseWriter, r *http.Request) {
+	contentType := exportContentType(r.Header.Get("Accept"))
+	if contentType == "" {
+		w.WriteHeader(http.StatusNotAcceptable)
+		errorResponse := entity2.Error{Message: ErrUnacceptableExport.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	filter, err := h.parseProductFilter(r)
+	if errors.Is(err, ErrOnlyAdminDeleted) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if err == nil {
+		_, err = database.ParseProductSort(r.URL.Query().Get("sort"))
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var encode func(product *entity.Product) error
+	flush := func() error { return nil }
+	extension := "ndjson"
+	if contentType == CSVContentType {
+		writer := csv.NewWriter(w)
+		encode = func(product *entity.Product) error {
+			return writer.Write(productCSVRecord(product))
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+		extension = "csv"
+		_ = writer.Write(productCSVHeader)
+	} else {
+		encoder := json.NewEncoder(w)
+		encode = func(product *entity.Product) error {
+			return encoder.Encode(product)
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="products.`+extension+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(w)
+	written := 0
+	err = h.ProductDB.Each(r.Context(), filter, r.URL.Query().Get("sort"), func(product *entity.Product) error {
+		if err := encode(product); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushEvery != 0 {
+			return nil
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		_ = controller.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+		return controller.Flush()
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		// the status was already sent, all we can do is cut the export short
+		logging.FromContext(r.Context()).Error("exporting products", "error", err)
+	}
+}
+
+// ImportProducts godoc
+//
+//	@Summary		Import products
+//	@Description	Create up to 10000 products, from a body of up to 32 MiB, from a CSV with a header line, where name and price columns are required, currency is USD when missing, tags are comma separated and unknown columns are ignored, or from newline delimited JSON objects like dto.CreateProductInput. Atomic imports nothing when a row is invalid, per_item imports the valid rows
+//	@Tags			products
+//	@Accept			text/csv,application/x-ndjson
+//	@Produce		json
+//	@Param			mode	query		string	false	"atomic by default"	Enums(atomic, per_item)
+//	@Param			request	body		string	true	"products file"
+//	@Success		201		{object}	dto.ImportProductsOutput
+//	@Success		207		{object}	dto.ImportProductsOutput
+//	@Failure		400		{object}	dto.ImportProductsOutput
+//	@Failure		413		{object}	entity.Error
+//	@Failure		415		{object}	entity.Error
+//	@Failure		500		{object}	entity.Error
+//	@Router			/products/import [post]
+//	@Security		ApiKeyAuth
+func (h *ProductHandler) ImportProducts(w http.ResponseWriter, r *http.Request) {
+	mode, err := bulkMode(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxImportSize)
+
+	var read func(io.Reader, importRowFunc) error
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case CSVContentType:
+		read = readCSVProducts
+	case NDJSONContentType, "application/ndjson":
+		read = readNDJSONProducts
+	default:
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		errorResponse := entity2.Error{Message: ErrUnsupportedImport.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	userID, _ := currentUser(r)
+	ownerID, err := entity2.ParseID(userID)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var output dto.ImportProductsOutput
+	var products []*entity.Product
+	rows := 0
+	err = read(r.Body, func(line int, input dto.CreateProductInput, err error) error {
+		rows++
+		if rows > MaxImportRows {
+			return ErrTooManyImportRows
+		}
+
+		var product *entity.Product
+		if err == nil {
+			product, err = entity.NewProduct(input.Name, input.Description, input.Price)
+		}
+		if err == nil {
+			err = product.SetTags(input.Tags)
+		}
+		if err != nil {
+			output.Errors = append(output.Errors, dto.ImportRowError{Line: line, Error: err.Error()})
+			return nil
+		}
+		product.OwnerID = ownerID
+		products = append(products, product)
+		return nil
+	})
+	if err == nil && rows == 0 {
+		err = ErrEmptyImport
+	}
+	if err != nil {
+		status := http.StatusBadRequest
+		var maxBytesErr *http.MaxBytesError
+		if errors.Is(err, ErrTooManyImportRows) || errors.As(err, &maxBytesErr) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		w.WriteHeader(status)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	status := http.StatusCreated
+	if len(output.Errors) > 0 {
+		status = http.StatusMultiStatus
+		if mode == BulkModeAtomic {
+			products, status = nil, http.StatusBadRequest
+		}
+	}
+
+	if len(products) > 0 {
+		err = h.ProductDB.CreateBatch(r.Context(), products)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			errorResponse := entity2.Error{Message: err.Error()}
+			_ = json.NewEncoder(w).Encode(errorResponse)
+			return
+		}
+	}
+	output.Imported = len(products)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(output)
+}
+
+// exportContentType returns the first export format accepted by the Accept
+// header, CSV when it is empty or accepts anything, or "" when none is.
+func exportContentType(accept string) string {
+	if strings.TrimSpace(accept) == "" {
+		return CSVContentType
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil || params["q"] == "0" {
+			continue
+		}
+		switch mediaType {
+		case CSVContentType, "text/*", "*/*":
+			return CSVContentType
+		case NDJSONContentType, "application/ndjson":
+			return NDJSONContentType
+		}
+	}
+	return ""
+}
+
+func productCSVRecord(product *entity.Product) []string {
+	deletedAt := ""
+	if product.IsDeleted() {
+		deletedAt = product.DeletedAt.Time.Format(time.RFC3339Nano)
+	}
+	return []string{
+		product.ID.String(),
+		product.Name,
+		product.Description,
+		product.Price.Decimal(),
+		product.Price.Currency,
+		product.OwnerID.String(),
+		strconv.Itoa(product.Version),
+		product.CreatedAt.Format(time.RFC3339Nano),
+		deletedAt,
+		strings.Join(product.Tags, ","),
+	}
+}
+
+// importRowFunc receives every product read from an import file with its
+// line, or the error that made the row unreadable. Returning an error stops the import.
+type importRowFunc func(line int, input dto.CreateProductInput, err error) error
+
+// readCSVProducts reads a CSV whose first line names its columns, so the
+// files written by ExportProducts can be imported back.
+func readCSVProducts(body io.Reader, row importRowFunc) error {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"name", "price"} {
+		if _, ok := columns[required]; !ok {
+			return fmt.Errorf("%w: %s", ErrMissingCSVColumn, required)
+		}
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line, _ := reader.FieldPos(0)
+		input := dto.CreateProductInput{
+			Name:        field(record, "name"),
+			Description: field(record, "description"),
+		}
+		if tags := field(record, "tags"); tags != "" {
+			input.Tags = strings.Split(tags, ",")
+		}
+		var rowErr error
+		if price := field(record, "price"); price != "" {
+			currency := field(record, "currency")
+			if currency == "" {
+				currency = entity2.DefaultCurrency
+			}
+			input.Price, rowErr = entity2.ParseMoney(price, currency)
+		}
+		if err = row(line, input, rowErr); err != nil {
+			return err
+		}
+	}
+}
+
+// readNDJSONProducts reads one JSON product per line, skipping blank lines.
+func readNDJSONProducts(body io.Reader, row importRowFunc) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var input dto.CreateProductInput
+		rowErr := json.Unmarshal(text, &input)
+		if err := row(line, input, rowErr); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"github.com/SchunckLeonardo/go-expert-api/internal/dto"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type importedRow struct {
+	line  int
+	input dto.CreateProductInput
+	err   error
+}
+
+func collectRows(rows *[]importedRow) importRowFunc {
+	return func(line int, input dto.CreateProductInput, err error) error {
+		*rows = append(*rows, importedRow{line: line, input: input, err: err})
+		return nil
+	}
+}
+
+func TestExportContentType(t *testing.T) {
+	assert.Equal(t, CSVContentType, exportContentType(""))
+	assert.Equal(t, CSVContentType, exportContentType("*/*"))
+	assert.Equal(t, NDJSONContentType, exportContentType("application/x-ndjson"))
+	assert.Equal(t, NDJSONContentType, exportContentType("application/json;q=0.9, application/ndjson"))
+	assert.Equal(t, NDJSONContentType, exportContentType("text/csv;q=0, application/x-ndjson"))
+	assert.Equal(t, "", exportContentType("application/xml"))
+}
+
+func TestReadCSVProducts(t *testing.T) {
+	body := "\ufeffID, Name ,Price,Description,Currency\n" +
+		"1,Laptop,1100.5,\"Macbook, M1\"\n" +
+		"2,Mouse,abc\n" +
+		"3,Pen,150,,JPY\n" +
+		"3,\"Multi\nline\",10\n"
+
+	var rows []importedRow
+	assert.Nil(t, readCSVProducts(strings.NewReader(body), collectRows(&rows)))
+	assert.Len(t, rows, 4)
+
+	assert.Equal(t, 2, rows[0].line)
+	assert.Nil(t, rows[0].err)
+	assert.Equal(t, dto.CreateProductInput{Name: "Laptop", Description: "Macbook, M1", Price: usd(110050)}, rows[0].input)
+
+	assert.Equal(t, 3, rows[1].line)
+	assert.ErrorIs(t, rows[1].err, entity2.ErrInvalidAmount)
+
+	assert.Equal(t, entity2.Money{Amount: 150, Currency: "JPY"}, rows[2].input.Price)
+
+	assert.Equal(t, 5, rows[3].line)
+	assert.Equal(t, "Multi\nline", rows[3].input.Name)
+}
+
+func TestReadCSVProducts_Tags(t *testing.T) {
+	body := "name,price,tags\n" +
+		"Laptop,1100,\"new,clearance\"\n" +
+		"Mouse,50,\n"
+
+	var rows []importedRow
+	assert.Nil(t, readCSVProducts(strings.NewReader(body), collectRows(&rows)))
+	assert.Len(t, rows, 2)
+	assert.Equal(t, []string{"new", "clearance"}, rows[0].input.Tags)
+	assert.Nil(t, rows[1].input.Tags)
+}
+
+func TestReadCSVProducts_MissingColumn(t *testing.T) {
+	var rows []importedRow
+	err := readCSVProducts(strings.NewReader("name,description\nLaptop,Macbook\n"), collectRows(&rows))
+	assert.ErrorIs(t, err, ErrMissingCSVColumn)
+	assert.Empty(t, rows)
+}
+
+func TestReadNDJSONProducts(t *testing.T) {
+	body := `{"name": "Laptop", "price": 1100}` + "\n\n" + `{"name": "Mouse"` + "\n" + `{"name": "Pen", "price": {"amount": "2.10", "currency": "EUR"}}`
+
+	var rows []importedRow
+	assert.Nil(t, readNDJSONProducts(strings.NewReader(body), collectRows(&rows)))
+	assert.Len(t, rows, 3)
+	assert.Equal(t, "Laptop", rows[0].input.Name)
+	assert.Equal(t, 3, rows[1].line)
+	assert.Error(t, rows[1].err)
+	assert.Equal(t, 4, rows[2].line)
+	assert.Equal(t, entity2.Money{Amount: 210, Currency: "EUR"}, rows[2].input.Price)
+}
+
+func TestImportProducts_TooLarge(t *testing.T) {
+	h := &ProductHandler{}
+	router := newTestRouter()
+	router.Post("/products/import", h.ImportProducts)
+	_, token, err := testTokenAuth.Encode(map[string]interface{}{"sub": entity2.NewID().String(), "role": string(entity.RoleEditor)})
+	assert.Nil(t, err)
+
+	ndjsonLine := `{"name":"Laptop","description":"` + strings.Repeat("a", maxNDJSONLine/2) + `","price":{"amount":"10.00"}}` + "\n"
+	bodies := map[string]string{
+		CSVContentType:    "name,price,description\nLaptop,10.00," + strings.Repeat("a", MaxImportSize) + "\n",
+		NDJSONContentType: strings.Repeat(ndjsonLine, MaxImportSize/len(ndjsonLine)+1),
+	}
+	for contentType, body := range bodies {
+		r := httptest.NewRequest(http.MethodPost, "/products/import", strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer "+token)
+		r.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, contentType)
+	}
+}
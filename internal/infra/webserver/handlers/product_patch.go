@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"reflect"
+	"strings"
+)
+
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+var (
+	ErrPatchTestFailed = errors.New("json patch test operation failed")
+	ErrInvalidPatch    = errors.New("invalid patch")
+)
+
+// patchableProductFields are the product fields a patch can change.
+var patchableProductFields = []string{"name", "description", "price", "tags"}
+
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// applyMergePatch applies an RFC 7396 merge patch to product. A null member
+// clears the field, an absent one leaves it unchanged, and an object is
+// merged into the field, so {"price": {"currency": "EUR"}} keeps the amount.
+func applyMergePatch(product *entity.Product, patch []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &members); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	if members == nil {
+		return fmt.Errorf("%w: merge patch must be an object", ErrInvalidPatch)
+	}
+
+	doc := productDocument(product)
+	for field, value := range members {
+		if !isPatchable(field) {
+			return fmt.Errorf("%w: %s can't be changed", ErrInvalidPatch, field)
+		}
+		if isNull(value) {
+			delete(doc, field)
+			continue
+		}
+		merged, err := mergeValue(doc[field], value)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidPatch, field, err)
+		}
+		doc[field] = merged
+	}
+
+	return applyProductDocument(product, doc)
+}
+
+// mergeValue merges patch into target as RFC 7396 does: when patch is an
+// object, its members are merged into those of target, null ones removing
+// them, otherwise patch replaces target.
+func mergeValue(target, patch json.RawMessage) (json.RawMessage, error) {
+	var patchMembers map[string]json.RawMessage
+	if json.Unmarshal(patch, &patchMembers) != nil || patchMembers == nil {
+		return patch, nil
+	}
+
+	var members map[string]json.RawMessage
+	if json.Unmarshal(target, &members) != nil || members == nil {
+		members = make(map[string]json.RawMessage)
+	}
+	for name, value := range patchMembers {
+		if isNull(value) {
+			delete(members, name)
+			continue
+		}
+		merged, err := mergeValue(members[name], value)
+		if err != nil {
+			return nil, err
+		}
+		members[name] = merged
+	}
+	return json.Marshal(members)
+}
+
+// applyJSONPatch applies an RFC 6902 JSON patch to product. Removing a field
+// clears it.
+func applyJSONPatch(product *entity.Product, patch []byte) error {
+	var operations []jsonPatchOperation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	doc := productDocument(product)
+	for _, operation := range operations {
+		field, err := patchPathField(operation.Path)
+		if err != nil {
+			return err
+		}
+
+		switch operation.Op {
+		case "add", "replace":
+			if operation.Value == nil {
+				return fmt.Errorf("%w: %s needs a value", ErrInvalidPatch, operation.Op)
+			}
+			if _, ok := doc[field]; !ok && operation.Op == "replace" {
+				return fmt.Errorf("%w: %s is not set", ErrInvalidPatch, operation.Path)
+			}
+			doc[field] = operation.Value
+		case "remove":
+			if _, ok := doc[field]; !ok {
+				return fmt.Errorf("%w: %s is not set", ErrInvalidPatch, operation.Path)
+			}
+			delete(doc, field)
+		case "test":
+			if !jsonEqual(doc[field], operation.Value) {
+				return fmt.Errorf("%w: %s", ErrPatchTestFailed, operation.Path)
+			}
+		case "copy", "move":
+			from, err := patchPathField(operation.From)
+			if err != nil {
+				return err
+			}
+			value, ok := doc[from]
+			if !ok {
+				return fmt.Errorf("%w: %s is not set", ErrInvalidPatch, operation.From)
+			}
+			if operation.Op == "move" {
+				delete(doc, from)
+			}
+			doc[field] = value
+		default:
+			return fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, operation.Op)
+		}
+	}
+
+	return applyProductDocument(product, doc)
+}
+
+// productDocument returns the patchable fields of product as JSON members.
+// Zero values are left out, so they behave as absent members.
+func productDocument(product *entity.Product) map[string]json.RawMessage {
+	doc := make(map[string]json.RawMessage)
+	if product.Name != "" {
+		doc["name"], _ = json.Marshal(product.Name)
+	}
+	if product.Description != "" {
+		doc["description"], _ = json.Marshal(product.Description)
+	}
+	if product.Price.Amount != 0 {
+		doc["price"], _ = json.Marshal(product.Price)
+	}
+	if len(product.Tags) > 0 {
+		doc["tags"], _ = json.Marshal(product.Tags)
+	}
+	return doc
+}
+
+func applyProductDocument(product *entity.Product, doc map[string]json.RawMessage) error {
+	patched := *product
+	patched.Name, patched.Description, patched.Price = "", "", entity2.Money{}
+	var tags []string
+
+	for field, value := range doc {
+		var target interface{}
+		switch field {
+		case "name":
+			target = &patched.Name
+		case "description":
+			target = &patched.Description
+		case "price":
+			target = &patched.Price
+		case "tags":
+			target = &tags
+		}
+		if err := json.Unmarshal(value, target); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidPatch, field, err)
+		}
+	}
+
+	if err := patched.SetTags(tags); err != nil {
+		return fmt.Errorf("%w: tags: %w", ErrInvalidPatch, err)
+	}
+
+	if err := patched.Validate(); err != nil {
+		return err
+	}
+
+	*product = patched
+	return nil
+}
+
+func patchPathField(path string) (string, error) {
+	field := strings.TrimPrefix(path, "/")
+	if !strings.HasPrefix(path, "/") || !isPatchable(field) {
+		return "", fmt.Errorf("%w: path %q can't be changed", ErrInvalidPatch, path)
+	}
+	return field, nil
+}
+
+func isPatchable(field string) bool {
+	for _, patchable := range patchableProductFields {
+		if field == patchable {
+			return true
+		}
+	}
+	return false
+}
+
+func isNull(value json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(value), []byte("null"))
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var left, right interface{}
+	if a == nil {
+		a = json.RawMessage("null")
+	}
+	if b == nil {
+		b = json.RawMessage("null")
+	}
+	if json.Unmarshal(a, &left) != nil || json.Unmarshal(b, &right) != nil {
+		return false
+	}
+	return reflect.DeepEqual(left, right)
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func usd(amount int64) entity2.Money {
+	return entity2.Money{Amount: amount, Currency: entity2.DefaultCurrency}
+}
+
+func newPatchProduct(t *testing.T) *entity.Product {
+	product, err := entity.NewProduct("Laptop", "Macbook M1", usd(110000))
+	assert.Nil(t, err)
+	return product
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	product := newPatchProduct(t)
+
+	err := applyMergePatch(product, []byte(`{"description": null, "price": 0.5}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "Laptop", product.Name)
+	assert.Equal(t, "", product.Description)
+	assert.Equal(t, usd(50), product.Price)
+
+	err = applyMergePatch(product, []byte(`{"name": "Notebook"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "Notebook", product.Name)
+	assert.Equal(t, usd(50), product.Price)
+
+	err = applyMergePatch(product, []byte(`{"price": {"amount": "1200", "currency": "JPY"}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, entity2.Money{Amount: 1200, Currency: "JPY"}, product.Price)
+}
+
+func TestApplyMergePatch_MergesObjects(t *testing.T) {
+	product := newPatchProduct(t)
+
+	err := applyMergePatch(product, []byte(`{"price": {"currency": "EUR"}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, entity2.Money{Amount: 110000, Currency: "EUR"}, product.Price)
+
+	err = applyMergePatch(product, []byte(`{"price": {"amount": "999.90"}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, entity2.Money{Amount: 99990, Currency: "EUR"}, product.Price)
+
+	err = applyMergePatch(product, []byte(`{"price": {"currency": null}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, usd(99990), product.Price)
+
+	assert.ErrorIs(t, applyMergePatch(product, []byte(`{"price": {"amount": null}}`)), ErrInvalidPatch)
+	assert.Equal(t, usd(99990), product.Price)
+}
+
+func TestApplyMergePatch_Tags(t *testing.T) {
+	product := newPatchProduct(t)
+
+	err := applyMergePatch(product, []byte(`{"tags": ["New", "clearance"]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"clearance", "new"}, product.Tags)
+
+	err = applyMergePatch(product, []byte(`{"name": "Notebook"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"clearance", "new"}, product.Tags)
+
+	assert.ErrorIs(t, applyMergePatch(product, []byte(`{"tags": ["a,b"]}`)), entity.ErrInvalidTag)
+	assert.Equal(t, []string{"clearance", "new"}, product.Tags)
+
+	err = applyMergePatch(product, []byte(`{"tags": null}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{}, product.Tags)
+}
+
+func TestApplyMergePatch_Invalid(t *testing.T) {
+	product := newPatchProduct(t)
+
+	assert.Equal(t, entity.ErrNameIsRequired, applyMergePatch(product, []byte(`{"name": null}`)))
+	assert.Equal(t, entity.ErrPriceIsRequired, applyMergePatch(product, []byte(`{"price": null}`)))
+	assert.Equal(t, entity.ErrInvalidPrice, applyMergePatch(product, []byte(`{"price": -1}`)))
+	assert.ErrorIs(t, applyMergePatch(product, []byte(`{"version": 10}`)), ErrInvalidPatch)
+	assert.ErrorIs(t, applyMergePatch(product, []byte(`{"price": "free"}`)), ErrInvalidPatch)
+	assert.ErrorIs(t, applyMergePatch(product, []byte(`{"price": 0.001}`)), entity2.ErrInvalidAmount)
+	assert.ErrorIs(t, applyMergePatch(product, []byte(`[]`)), ErrInvalidPatch)
+	assert.ErrorIs(t, applyMergePatch(product, []byte(`null`)), ErrInvalidPatch)
+
+	assert.Equal(t, "Laptop", product.Name)
+	assert.Equal(t, "Macbook M1", product.Description)
+	assert.Equal(t, usd(110000), product.Price)
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	product := newPatchProduct(t)
+
+	err := applyJSONPatch(product, []byte(`[
+		{"op": "test", "path": "/name", "value": "Laptop"},
+		{"op": "replace", "path": "/price", "value": 0.5},
+		{"op": "remove", "path": "/description"}
+	]`))
+	assert.Nil(t, err)
+	assert.Equal(t, "Laptop", product.Name)
+	assert.Equal(t, "", product.Description)
+	assert.Equal(t, usd(50), product.Price)
+
+	err = applyJSONPatch(product, []byte(`[
+		{"op": "copy", "from": "/name", "path": "/description"},
+		{"op": "add", "path": "/name", "value": "Notebook"}
+	]`))
+	assert.Nil(t, err)
+	assert.Equal(t, "Notebook", product.Name)
+	assert.Equal(t, "Laptop", product.Description)
+}
+
+func TestApplyJSONPatch_Invalid(t *testing.T) {
+	product := newPatchProduct(t)
+
+	assert.ErrorIs(t, applyJSONPatch(product, []byte(`[{"op": "test", "path": "/name", "value": "Phone"}]`)), ErrPatchTestFailed)
+	assert.ErrorIs(t, applyJSONPatch(product, []byte(`[{"op": "replace", "path": "/id", "value": "1"}]`)), ErrInvalidPatch)
+	assert.ErrorIs(t, applyJSONPatch(product, []byte(`[{"op": "explode", "path": "/name"}]`)), ErrInvalidPatch)
+	assert.ErrorIs(t, applyJSONPatch(product, []byte(`[{"op": "replace", "path": "/name"}]`)), ErrInvalidPatch)
+	assert.ErrorIs(t, applyJSONPatch(product, []byte(`{"op": "remove", "path": "/name"}`)), ErrInvalidPatch)
+	assert.Equal(t, entity.ErrNameIsRequired, applyJSONPatch(product, []byte(`[{"op": "remove", "path": "/name"}]`)))
+
+	assert.Equal(t, "Laptop", product.Name)
+	assert.Equal(t, usd(110000), product.Price)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"net/http"
+)
+
+type TagHandler struct {
+	TagDB database.TagInterface
+}
+
+func NewTagHandler(db database.TagInterface) *TagHandler {
+	return &TagHandler{TagDB: db}
+}
+
+// ListTags godoc
+//
+//	@Summary		List tags
+//	@Description	List the tags of the products that aren't deleted with how many products have each one, the most used first
+//	@Tags			tags
+//	@Produce		json
+//	@Success		200	{array}		entity.TagUsage
+//	@Failure		500	{object}	entity.Error
+//	@Router			/tags [get]
+//	@Security		ApiKeyAuth
+func (h *TagHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.TagDB.FindAll(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(tags)
+}
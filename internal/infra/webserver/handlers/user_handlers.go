@@ -2,26 +2,43 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/SchunckLeonardo/go-expert-api/internal/dto"
 	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
 	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
 	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/jwtauth"
+	"gorm.io/gorm"
+	"io"
 	"net/http"
 	"time"
 )
 
 type UserHandler struct {
-	UserDB       database.UserInterface
-	Jwt          *jwtauth.JWTAuth
-	JwtExpiresIn int
+	UserDB              database.UserInterface
+	RefreshTokenDB      database.RefreshTokenInterface
+	RevokedTokenDB      database.RevokedTokenInterface
+	Jwt                 *jwtauth.JWTAuth
+	JwtExpiresIn        int
+	JwtRefreshExpiresIn int
 }
 
-func NewUserHandler(userDB database.UserInterface, jwt *jwtauth.JWTAuth, jwtExpiresIn int) *UserHandler {
+func NewUserHandler(
+	userDB database.UserInterface,
+	refreshTokenDB database.RefreshTokenInterface,
+	revokedTokenDB database.RevokedTokenInterface,
+	jwt *jwtauth.JWTAuth,
+	jwtExpiresIn int,
+	jwtRefreshExpiresIn int,
+) *UserHandler {
 	return &UserHandler{
-		UserDB:       userDB,
-		Jwt:          jwt,
-		JwtExpiresIn: jwtExpiresIn,
+		UserDB:              userDB,
+		RefreshTokenDB:      refreshTokenDB,
+		RevokedTokenDB:      revokedTokenDB,
+		Jwt:                 jwt,
+		JwtExpiresIn:        jwtExpiresIn,
+		JwtRefreshExpiresIn: jwtRefreshExpiresIn,
 	}
 }
 
@@ -55,7 +72,7 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.UserDB.Create(user)
+	err = h.UserDB.Create(r.Context(), user)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		errorResponse := entity2.Error{Message: err.Error()}
@@ -86,7 +103,7 @@ func (h *UserHandler) GetJWT(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
-	user, err := h.UserDB.FindByEmail(userJwtDto.Email)
+	user, err := h.UserDB.FindByEmail(r.Context(), userJwtDto.Email)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		errorResponse := entity2.Error{Message: err.Error()}
@@ -95,20 +112,263 @@ func (h *UserHandler) GetJWT(w http.ResponseWriter, r *http.Request) {
 	}
 	if !user.ValidatePassword(userJwtDto.Password) {
 		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: entity.ErrInvalidCredentials.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	refreshToken, token, err := entity.NewRefreshToken(user.ID, entity2.NewID(), h.refreshExpiresIn())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
 		errorResponse := entity2.Error{Message: err.Error()}
 		_ = json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
 
-	payload := map[string]interface{}{
-		"sub": user.ID.String(),
-		"exp": time.Now().Add(time.Second * time.Duration(h.JwtExpiresIn)).Unix(),
+	err = h.RefreshTokenDB.Create(r.Context(), refreshToken)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	accessToken, err := h.accessToken(user)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(dto.GetJWTOutput{AccessToken: accessToken, RefreshToken: token})
+}
+
+// RefreshJWT godoc
+//
+//	@Summary		Refresh a user JWT
+//	@Description	Exchange a refresh token for a new access and refresh token pair. The refresh token is rotated and can only be used once
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.RefreshJWTInput	true	"refresh token"
+//	@Success		200		{object}	dto.GetJWTOutput
+//	@Failure		400		{object}	entity.Error
+//	@Failure		401		{object}	entity.Error
+//	@Failure		500		{object}	entity.Error
+//	@Router			/sessions/refresh [post]
+func (h *UserHandler) RefreshJWT(w http.ResponseWriter, r *http.Request) {
+	var refreshDto dto.RefreshJWTInput
+	err := json.NewDecoder(r.Body).Decode(&refreshDto)
+	if err != nil || refreshDto.RefreshToken == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: entity.ErrRefreshTokenInvalid.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	current, err := h.RefreshTokenDB.FindByHash(r.Context(), entity.HashRefreshToken(refreshDto.RefreshToken))
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := entity2.Error{Message: entity.ErrRefreshTokenInvalid.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if current.IsRevoked() {
+		// a rotated token was presented again, so it may have leaked: revoke its whole family
+		_ = h.RefreshTokenDB.RevokeFamily(r.Context(), current.FamilyID.String())
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := entity2.Error{Message: entity.ErrRefreshTokenRevoked.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if current.IsExpired() {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := entity2.Error{Message: entity.ErrRefreshTokenExpired.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	// everything that can fail comes before Rotate, which uses current up
+	user, err := h.UserDB.FindByID(r.Context(), current.UserID.String())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := entity2.Error{Message: entity.ErrRefreshTokenInvalid.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	accessToken, err := h.accessToken(user)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	next, token, err := entity.NewRefreshToken(current.UserID, current.FamilyID, h.refreshExpiresIn())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = h.RefreshTokenDB.Rotate(r.Context(), current, next)
+	if errors.Is(err, entity.ErrRefreshTokenRevoked) {
+		_ = h.RefreshTokenDB.RevokeFamily(r.Context(), current.FamilyID.String())
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
 	}
 
-	_, token, _ := h.Jwt.Encode(payload)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(dto.GetJWTOutput{AccessToken: accessToken, RefreshToken: token})
+}
 
-	accessToken := dto.GetJWTOutput{AccessToken: token}
+// UpdateRole godoc
+//
+//	@Summary		Change the role of a user
+//	@Description	Change the role of a user. It takes effect on the next JWT issued to the user
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	string					true	"user ID"	Format(uuid)
+//	@Param			request	body	dto.UpdateUserRoleInput	true	"new role"
+//	@Success		200
+//	@Failure		400	{object}	entity.Error
+//	@Failure		403	{object}	entity.Error
+//	@Failure		404	{object}	entity.Error
+//	@Failure		500	{object}	entity.Error
+//	@Router			/users/{id}/role [put]
+//	@Security		ApiKeyAuth
+func (h *UserHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.UserDB.FindByID(r.Context(), id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var roleDto dto.UpdateUserRoleInput
+	err = json.NewDecoder(r.Body).Decode(&roleDto)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = user.SetRole(entity.Role(roleDto.Role))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = h.UserDB.Update(r.Context(), user)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
 
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(accessToken)
+}
+
+// Logout godoc
+//
+//	@Summary		Revoke a user JWT
+//	@Description	Revoke the access token used in the request. When a refresh token is sent, every token rotated from it is revoked as well
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body	dto.LogoutInput	false	"refresh token to revoke"
+//	@Success		204
+//	@Failure		400	{object}	entity.Error
+//	@Failure		401	{object}	entity.Error
+//	@Failure		500	{object}	entity.Error
+//	@Router			/sessions [delete]
+//	@Security		ApiKeyAuth
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	token, _, err := jwtauth.FromContext(r.Context())
+	if err != nil || token == nil || token.JwtID() == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		errorResponse := entity2.Error{Message: "token without jti can't be revoked"}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var logoutDto dto.LogoutInput
+	err = json.NewDecoder(r.Body).Decode(&logoutDto)
+	if err != nil && !errors.Is(err, io.EOF) {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = h.RevokedTokenDB.Create(r.Context(), entity.NewRevokedToken(token.JwtID(), token.Expiration()))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if logoutDto.RefreshToken != "" {
+		refreshToken, err := h.RefreshTokenDB.FindByHash(r.Context(), entity.HashRefreshToken(logoutDto.RefreshToken))
+		if err == nil && refreshToken.UserID.String() == token.Subject() {
+			err = h.RefreshTokenDB.RevokeFamily(r.Context(), refreshToken.FamilyID.String())
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				errorResponse := entity2.Error{Message: err.Error()}
+				_ = json.NewEncoder(w).Encode(errorResponse)
+				return
+			}
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) accessToken(user *entity.User) (string, error) {
+	payload := map[string]interface{}{
+		"sub":  user.ID.String(),
+		"role": string(user.Role),
+		"jti":  entity2.NewID().String(),
+		"exp":  time.Now().Add(time.Second * time.Duration(h.JwtExpiresIn)).Unix(),
+	}
+
+	_, token, err := h.Jwt.Encode(payload)
+	return token, err
+}
+
+func (h *UserHandler) refreshExpiresIn() time.Duration {
+	return time.Second * time.Duration(h.JwtRefreshExpiresIn)
 }
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/SchunckLeonardo/go-expert-api/internal/dto"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRefreshJWT_KeepsTokenWhenUserIsMissing(t *testing.T) {
+	ctx := context.Background()
+	db := utils.OpenDBConnection(t)
+	userDB := database.NewUser(db)
+	refreshTokenDB := database.NewRefreshToken(db)
+	h := NewUserHandler(userDB, refreshTokenDB, database.NewRevokedToken(db), testTokenAuth, 300, 3600)
+
+	refresh := func(token string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		assert.Nil(t, json.NewEncoder(&body).Encode(dto.RefreshJWTInput{RefreshToken: token}))
+		return serve(http.HandlerFunc(h.RefreshJWT), httptest.NewRequest(http.MethodPost, "/sessions/refresh", &body))
+	}
+
+	orphan, orphanToken, err := entity.NewRefreshToken(entity2.NewID(), entity2.NewID(), time.Hour)
+	assert.Nil(t, err)
+	assert.Nil(t, refreshTokenDB.Create(ctx, orphan))
+
+	w := refresh(orphanToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, entity.ErrRefreshTokenInvalid.Error(), decodeError(t, w))
+	stored, err := refreshTokenDB.FindByHash(ctx, orphan.TokenHash)
+	assert.Nil(t, err)
+	assert.False(t, stored.IsRevoked())
+
+	user, err := entity.NewUser("John", "john@example.com", "secret")
+	assert.Nil(t, err)
+	assert.Nil(t, userDB.Create(ctx, user))
+	current, token, err := entity.NewRefreshToken(user.ID, entity2.NewID(), time.Hour)
+	assert.Nil(t, err)
+	assert.Nil(t, refreshTokenDB.Create(ctx, current))
+
+	w = refresh(token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var output dto.GetJWTOutput
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&output))
+	assert.NotEmpty(t, output.AccessToken)
+	assert.NotEqual(t, token, output.RefreshToken)
+	stored, err = refreshTokenDB.FindByHash(ctx, current.TokenHash)
+	assert.Nil(t, err)
+	assert.True(t, stored.IsRevoked())
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/SchunckLeonardo/go-expert-api/internal/dto"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type VariantHandler struct {
+	VariantDB database.VariantInterface
+	ProductDB database.ProductInterface
+}
+
+func NewVariantHandler(db database.VariantInterface, productDB database.ProductInterface) *VariantHandler {
+	return &VariantHandler{VariantDB: db, ProductDB: productDB}
+}
+
+// ListVariants godoc
+//
+//	@Summary		List the variants of a product
+//	@Description	List the variants of a product ordered by SKU
+//	@Tags			variants
+//	@Produce		json
+//	@Param			id	path		string	true	"product ID"	Format(uuid)
+//	@Success		200	{array}		entity.Variant
+//	@Failure		404	{object}	entity.Error
+//	@Failure		500	{object}	entity.Error
+//	@Router			/products/{id}/variants [get]
+//	@Security		ApiKeyAuth
+func (h *VariantHandler) ListVariants(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	variants, err := h.VariantDB.FindByProduct(r.Context(), product.ID.String())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(variants)
+}
+
+// CreateVariant godoc
+//
+//	@Summary		Create a variant
+//	@Description	Create a variant of a product with a SKU unique among all variants, uppercased, and options like {"size": "M"} with lowercased names
+//	@Tags			variants
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"product ID"	Format(uuid)
+//	@Param			request	body		dto.VariantInput	true	"variant request"
+//	@Success		201		{object}	entity.Variant
+//	@Failure		400		{object}	entity.Error
+//	@Failure		403		{object}	entity.Error
+//	@Failure		404		{object}	entity.Error
+//	@Failure		409		{object}	entity.Error
+//	@Failure		500		{object}	entity.Error
+//	@Router			/products/{id}/variants [post]
+//	@Security		ApiKeyAuth
+func (h *VariantHandler) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if !canModify(r, product) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := entity2.Error{Message: ErrNotProductOwner.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var variantDTO dto.VariantInput
+	err = json.NewDecoder(r.Body).Decode(&variantDTO)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	variant, err := entity.NewVariant(product.ID, variantDTO.SKU, variantDTO.Options, variantDTO.Price)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = h.VariantDB.Create(r.Context(), variant)
+	if err != nil {
+		w.WriteHeader(variantErrorStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(variant)
+}
+
+// GetVariant godoc
+//
+//	@Summary		Get a variant
+//	@Description	Get a variant of a product
+//	@Tags			variants
+//	@Produce		json
+//	@Param			id			path		string	true	"product ID"	Format(uuid)
+//	@Param			variant_id	path		string	true	"variant ID"	Format(uuid)
+//	@Success		200			{object}	entity.Variant
+//	@Failure		404			{object}	entity.Error
+//	@Router			/products/{id}/variants/{variant_id} [get]
+//	@Security		ApiKeyAuth
+func (h *VariantHandler) GetVariant(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	variant, err := h.VariantDB.FindByID(r.Context(), product.ID.String(), chi.URLParam(r, "variant_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(variant)
+}
+
+// UpdateVariant godoc
+//
+//	@Summary		Update a variant
+//	@Description	Replace the SKU, options and price of a variant, which is sold for the price of the product when price is empty
+//	@Tags			variants
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string				true	"product ID"	Format(uuid)
+//	@Param			variant_id	path		string				true	"variant ID"	Format(uuid)
+//	@Param			request		body		dto.VariantInput	true	"variant request"
+//	@Success		200			{object}	entity.Variant
+//	@Failure		400			{object}	entity.Error
+//	@Failure		403			{object}	entity.Error
+//	@Failure		404			{object}	entity.Error
+//	@Failure		409			{object}	entity.Error
+//	@Failure		500			{object}	entity.Error
+//	@Router			/products/{id}/variants/{variant_id} [put]
+//	@Security		ApiKeyAuth
+func (h *VariantHandler) UpdateVariant(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if !canModify(r, product) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := entity2.Error{Message: ErrNotProductOwner.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	variant, err := h.VariantDB.FindByID(r.Context(), product.ID.String(), chi.URLParam(r, "variant_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	var variantDTO dto.VariantInput
+	err = json.NewDecoder(r.Body).Decode(&variantDTO)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if err = variant.Set(variantDTO.SKU, variantDTO.Options, variantDTO.Price); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = h.VariantDB.Update(r.Context(), variant)
+	if err != nil {
+		w.WriteHeader(variantErrorStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(variant)
+}
+
+// DeleteVariant godoc
+//
+//	@Summary		Delete a variant
+//	@Description	Delete a variant of a product, freeing its SKU
+//	@Tags			variants
+//	@Param			id			path	string	true	"product ID"	Format(uuid)
+//	@Param			variant_id	path	string	true	"variant ID"	Format(uuid)
+//	@Success		200
+//	@Failure		403	{object}	entity.Error
+//	@Failure		404	{object}	entity.Error
+//	@Failure		500	{object}	entity.Error
+//	@Router			/products/{id}/variants/{variant_id} [delete]
+//	@Security		ApiKeyAuth
+func (h *VariantHandler) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	product, err := h.ProductDB.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	if !canModify(r, product) {
+		w.WriteHeader(http.StatusForbidden)
+		errorResponse := entity2.Error{Message: ErrNotProductOwner.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	variant, err := h.VariantDB.FindByID(r.Context(), product.ID.String(), chi.URLParam(r, "variant_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	err = h.VariantDB.Delete(r.Context(), variant.ID.String())
+	if err != nil {
+		w.WriteHeader(variantErrorStatus(err))
+		errorResponse := entity2.Error{Message: err.Error()}
+		_ = json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func variantErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, database.ErrSKUExists):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var testTokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+
+// newTestRouter returns a router verifying tokens like the API routes do,
+// for routes to be added to.
+func newTestRouter() *chi.Mux {
+	r := chi.NewRouter()
+	r.Use(jwtauth.Verifier(testTokenAuth))
+	r.Use(jwtauth.Authenticator)
+	return r
+}
+
+// requestAs returns a request with body as JSON from the user userID with role.
+func requestAs(t *testing.T, userID entity2.ID, role entity.Role, method, target string, body interface{}) *http.Request {
+	_, token, err := testTokenAuth.Encode(map[string]interface{}{"sub": userID.String(), "role": string(role)})
+	assert.Nil(t, err)
+
+	var payload bytes.Buffer
+	if body != nil {
+		assert.Nil(t, json.NewEncoder(&payload).Encode(body))
+	}
+	r := httptest.NewRequest(method, target, &payload)
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+func serve(router http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+// serveAs sends body as JSON to router as the user userID with role.
+func serveAs(t *testing.T, router http.Handler, userID entity2.ID, role entity.Role, method, target string, body interface{}) *httptest.ResponseRecorder {
+	return serve(router, requestAs(t, userID, role, method, target, body))
+}
+
+func decodeError(t *testing.T, w *httptest.ResponseRecorder) string {
+	var errorResponse entity2.Error
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&errorResponse))
+	return errorResponse.Message
+}
+
+func createOwnedProduct(t *testing.T, db *gorm.DB, name string, ownerID entity2.ID) *entity.Product {
+	product, err := entity.NewProduct(name, "", usd(10000))
+	assert.Nil(t, err)
+	product.OwnerID = ownerID
+	assert.Nil(t, database.NewProduct(db).Create(context.Background(), product))
+	return product
+}
+
+func TestVariantErrorStatus(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, variantErrorStatus(gorm.ErrRecordNotFound))
+	assert.Equal(t, http.StatusConflict, variantErrorStatus(database.ErrSKUExists))
+	assert.Equal(t, http.StatusInternalServerError, variantErrorStatus(errors.New("boom")))
+}
+
+func TestVariantRoutes(t *testing.T) {
+	db := utils.OpenDBConnection(t)
+	h := NewVariantHandler(database.NewVariant(db), database.NewProduct(db))
+	router := newTestRouter()
+	router.Post("/products/{id}/variants", h.CreateVariant)
+	router.Put("/products/{id}/variants/{variant_id}", h.UpdateVariant)
+
+	owner, other, admin := entity2.NewID(), entity2.NewID(), entity2.NewID()
+	shirt := createOwnedProduct(t, db, "Shirt", owner)
+	pants := createOwnedProduct(t, db, "Pants", other)
+	shirtVariants := "/products/" + shirt.ID.String() + "/variants"
+	pantsVariants := "/products/" + pants.ID.String() + "/variants"
+
+	w := serveAs(t, router, owner, entity.RoleEditor, http.MethodPost, shirtVariants, map[string]interface{}{"sku": "shirt-m", "options": map[string]string{"Size": "M"}})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var variant entity.Variant
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&variant))
+	assert.Equal(t, shirt.ID, variant.ProductID)
+	assert.Equal(t, "SHIRT-M", variant.SKU)
+	assert.Equal(t, map[string]string{"size": "M"}, variant.Options)
+
+	w = serveAs(t, router, other, entity.RoleEditor, http.MethodPost, pantsVariants, map[string]interface{}{"sku": "SHIRT-M"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, database.ErrSKUExists.Error(), decodeError(t, w))
+
+	w = serveAs(t, router, other, entity.RoleEditor, http.MethodPost, shirtVariants, map[string]interface{}{"sku": "SHIRT-L"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, ErrNotProductOwner.Error(), decodeError(t, w))
+
+	w = serveAs(t, router, admin, entity.RoleAdmin, http.MethodPost, shirtVariants, map[string]interface{}{"sku": "SHIRT-L"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = serveAs(t, router, other, entity.RoleEditor, http.MethodPut, shirtVariants+"/"+variant.ID.String(), map[string]interface{}{"sku": "SHIRT-S"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, ErrNotProductOwner.Error(), decodeError(t, w))
+
+	w = serveAs(t, router, other, entity.RoleEditor, http.MethodPut, pantsVariants+"/"+variant.ID.String(), map[string]interface{}{"sku": "SHIRT-S"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serveAs(t, router, owner, entity.RoleEditor, http.MethodPut, shirtVariants+"/"+variant.ID.String(), map[string]interface{}{"sku": "SHIRT-L"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, database.ErrSKUExists.Error(), decodeError(t, w))
+
+	w = serveAs(t, router, owner, entity.RoleEditor, http.MethodPut, shirtVariants+"/"+variant.ID.String(), map[string]interface{}{"sku": "shirt-s"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&variant))
+	assert.Equal(t, "SHIRT-S", variant.SKU)
+}
@@ -0,0 +1,18 @@
+package middlewares
+
+import (
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	"github.com/go-chi/jwtauth"
+	"net/http"
+)
+
+// IdentifyActor makes the subject of the verified token the actor of the
+// changes the request makes, see database.WithActor. It must run after
+// jwtauth.Authenticator.
+func IdentifyActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, claims, _ := jwtauth.FromContext(r.Context())
+		userID, _ := claims["sub"].(string)
+		next.ServeHTTP(w, r.WithContext(database.WithActor(r.Context(), userID)))
+	})
+}
@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/go-chi/jwtauth"
+	"net/http"
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+// RequirePermission responds 403 unless the role claim of the verified token
+// grants permission. It must run after jwtauth.Authenticator.
+func RequirePermission(permission entity.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, claims, _ := jwtauth.FromContext(r.Context())
+			role, _ := claims["role"].(string)
+
+			if !entity.Role(role).Can(permission) {
+				w.WriteHeader(http.StatusForbidden)
+				errorResponse := entity2.Error{Message: ErrForbidden.Error()}
+				_ = json.NewEncoder(w).Encode(errorResponse)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -1,13 +1,40 @@
 package middlewares
 
 import (
-	"log"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/logging"
+	"github.com/go-chi/chi/v5/middleware"
+	"log/slog"
 	"net/http"
+	"time"
 )
 
-func LogRequest(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("Request: %s %s", r.Method, r.URL.Path)
-		next.ServeHTTP(w, r)
-	})
+// LogRequest puts a logger carrying the request ID in the context of each
+// request, then logs the request once served with its status, size and
+// duration. It must run after RequestID.
+func LogRequest(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			requestLogger := logger.With("request_id", logging.RequestID(r.Context()))
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r.WithContext(logging.WithContext(r.Context(), requestLogger)))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			requestLogger.Log(r.Context(), level, "request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", status,
+				"bytes", ww.BytesWritten(),
+				"duration_ms", time.Since(start).Milliseconds(),
+			)
+		})
+	}
 }
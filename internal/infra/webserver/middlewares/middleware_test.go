@@ -0,0 +1,123 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/logging"
+	"github.com/SchunckLeonardo/go-expert-api/test/utils"
+	"github.com/go-chi/jwtauth"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var tokenAuth = jwtauth.New("HS256", []byte("secret"), nil)
+
+// serveWithToken serves a request carrying a token with claims through
+// middleware, behind the same verification as the API routes, and returns
+// the status.
+func serveWithToken(t *testing.T, middleware func(http.Handler) http.Handler, claims map[string]interface{}) int {
+	_, token, err := tokenAuth.Encode(claims)
+	assert.Nil(t, err)
+	r := httptest.NewRequest(http.MethodGet, "/products", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	w := httptest.NewRecorder()
+	jwtauth.Verifier(tokenAuth)(jwtauth.Authenticator(middleware(ok))).ServeHTTP(w, r)
+	return w.Code
+}
+
+func TestRequestID(t *testing.T) {
+	var got string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = logging.RequestID(r.Context())
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/products", nil)
+	r.Header.Set(RequestIDHeader, "upstream-42")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, "upstream-42", got)
+	assert.Equal(t, "upstream-42", w.Header().Get(RequestIDHeader))
+
+	for _, id := range []string{"", "has spaces", strings.Repeat("a", 65)} {
+		r = httptest.NewRequest(http.MethodGet, "/products", nil)
+		r.Header.Set(RequestIDHeader, id)
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		assert.NotEqual(t, id, got)
+		assert.Len(t, got, 36)
+		assert.Equal(t, got, w.Header().Get(RequestIDHeader))
+	}
+}
+
+func TestLogRequest(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := logging.New(&out, "info")
+	assert.Nil(t, err)
+	handler := RequestID(LogRequest(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logging.FromContext(r.Context()).Info("handling")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("not found"))
+	})))
+
+	r := httptest.NewRequest(http.MethodGet, "/products/1", nil)
+	r.Header.Set(RequestIDHeader, "abc")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"request_id":"abc"`)
+
+	var access map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &access))
+	assert.Equal(t, "request", access["msg"])
+	assert.Equal(t, "abc", access["request_id"])
+	assert.Equal(t, "GET", access["method"])
+	assert.Equal(t, "/products/1", access["path"])
+	assert.Equal(t, float64(http.StatusNotFound), access["status"])
+	assert.Equal(t, float64(len("not found")), access["bytes"])
+	assert.Contains(t, access, "duration_ms")
+}
+
+func TestRejectRevokedTokens(t *testing.T) {
+	store := database.NewRevokedToken(utils.OpenDBConnection(t))
+	assert.Nil(t, store.Create(context.Background(), entity.NewRevokedToken("revoked", time.Now().Add(time.Hour))))
+	middleware := RejectRevokedTokens(store)
+
+	assert.Equal(t, http.StatusUnauthorized, serveWithToken(t, middleware, map[string]interface{}{"sub": "1", "jti": "revoked"}))
+	assert.Equal(t, http.StatusOK, serveWithToken(t, middleware, map[string]interface{}{"sub": "1", "jti": "valid"}))
+	assert.Equal(t, http.StatusOK, serveWithToken(t, middleware, map[string]interface{}{"sub": "1"}))
+}
+
+func TestRequirePermission(t *testing.T) {
+	middleware := RequirePermission(entity.PermissionWriteProducts)
+
+	assert.Equal(t, http.StatusForbidden, serveWithToken(t, middleware, map[string]interface{}{"sub": "1", "role": string(entity.RoleViewer)}))
+	assert.Equal(t, http.StatusOK, serveWithToken(t, middleware, map[string]interface{}{"sub": "1", "role": string(entity.RoleEditor)}))
+	assert.Equal(t, http.StatusOK, serveWithToken(t, middleware, map[string]interface{}{"sub": "1", "role": string(entity.RoleAdmin)}))
+	assert.Equal(t, http.StatusForbidden, serveWithToken(t, middleware, map[string]interface{}{"sub": "1", "role": "root"}))
+	assert.Equal(t, http.StatusForbidden, serveWithToken(t, middleware, map[string]interface{}{"sub": "1"}))
+}
+
+func TestRequirePermission_ForbiddenBody(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/products", nil)
+	w := httptest.NewRecorder()
+	RequirePermission(entity.PermissionWriteProducts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("request without a token reached the handler")
+	})).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	var body map[string]string
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, ErrForbidden.Error(), body["message"])
+}
@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database"
+	entity2 "github.com/SchunckLeonardo/go-expert-api/pkg/entity"
+	"github.com/go-chi/jwtauth"
+	"net/http"
+)
+
+// RejectRevokedTokens responds 401 when the jti of the verified token is in
+// the revocation store. It must run after jwtauth.Verifier.
+func RejectRevokedTokens(store database.RevokedTokenInterface) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, _, err := jwtauth.FromContext(r.Context())
+			if err != nil || token == nil || token.JwtID() == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			revoked, err := store.IsRevoked(r.Context(), token.JwtID())
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				errorResponse := entity2.Error{Message: err.Error()}
+				_ = json.NewEncoder(w).Encode(errorResponse)
+				return
+			}
+			if revoked {
+				w.WriteHeader(http.StatusUnauthorized)
+				errorResponse := entity2.Error{Message: entity.ErrTokenRevoked.Error()}
+				_ = json.NewEncoder(w).Encode(errorResponse)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency of amounts that don't name one.
+const DefaultCurrency = "USD"
+
+var (
+	ErrInvalidAmount   = errors.New("invalid amount")
+	ErrInvalidCurrency = errors.New("invalid currency")
+)
+
+// currencyExponents holds the supported ISO 4217 currencies with their
+// number of decimal places.
+var currencyExponents = map[string]int{
+	"ARS": 2,
+	"AUD": 2,
+	"BHD": 3,
+	"BRL": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"CLP": 0,
+	"CNY": 2,
+	"DKK": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"INR": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"MXN": 2,
+	"NOK": 2,
+	"SEK": 2,
+	"USD": 2,
+	"ZAR": 2,
+}
+
+// Money is an exact amount of a currency, counted in its minor units
+// (e.g. cents). It is encoded in JSON as {"amount": "10.50", "currency": "USD"}.
+type Money struct {
+	Amount   int64  `json:"amount" swaggertype:"string" example:"10.50"`
+	Currency string `json:"currency" example:"USD"`
+}
+
+// NewMoney returns amount minor units of currency.
+func NewMoney(amount int64, currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+	if !IsCurrency(currency) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidCurrency, currency)
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// ParseMoney parses a decimal amount of currency, like "10.50", without
+// rounding it. Amounts with more decimal places than the currency has are invalid.
+func ParseMoney(amount, currency string) (Money, error) {
+	money, err := NewMoney(0, currency)
+	if err != nil {
+		return Money{}, err
+	}
+	exponent := money.Exponent()
+
+	value := strings.TrimSpace(amount)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+	units, fraction, _ := strings.Cut(value, ".")
+	if units == "" || !isDigits(units) || !isDigits(fraction) || (strings.Contains(value, ".") && fraction == "") {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > exponent {
+		return Money{}, fmt.Errorf("%w: %q has more than %d decimal places for %s", ErrInvalidAmount, amount, exponent, money.Currency)
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	minor, err := strconv.ParseInt(units+fraction, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	if negative {
+		minor = -minor
+	}
+	money.Amount = minor
+	return money, nil
+}
+
+// IsCurrency reports whether currency is a supported ISO 4217 code.
+func IsCurrency(currency string) bool {
+	_, ok := currencyExponents[currency]
+	return ok
+}
+
+// Exponent returns the number of decimal places of the currency of m.
+func (m Money) Exponent() int {
+	return currencyExponents[m.Currency]
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0 && m.Currency == ""
+}
+
+// Decimal returns the exact decimal amount of m, like "10.50".
+func (m Money) Decimal() string {
+	exponent := m.Exponent()
+	digits := strconv.FormatUint(absolute(m.Amount), 10)
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	sign := ""
+	if m.Amount < 0 {
+		sign = "-"
+	}
+	if exponent == 0 {
+		return sign + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.Decimal(), m.Currency})
+}
+
+// UnmarshalJSON accepts {"amount": "10.50", "currency": "USD"}, where the
+// amount can also be a JSON number and the currency defaults to
+// DefaultCurrency, or just the amount. Numbers are parsed from their text,
+// so they are never rounded.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var value struct {
+		Amount   json.RawMessage `json:"amount"`
+		Currency string          `json:"currency"`
+	}
+	if bytes.HasPrefix(data, []byte("{")) {
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+	} else {
+		value.Amount = data
+	}
+	if value.Currency == "" {
+		value.Currency = DefaultCurrency
+	}
+
+	amount := string(value.Amount)
+	if strings.HasPrefix(amount, `"`) {
+		if err := json.Unmarshal(value.Amount, &amount); err != nil {
+			return err
+		}
+	}
+
+	money, err := ParseMoney(amount, value.Currency)
+	if err != nil {
+		return err
+	}
+	*m = money
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func absolute(amount int64) uint64 {
+	if amount == math.MinInt64 {
+		return uint64(math.MaxInt64) + 1
+	}
+	if amount < 0 {
+		return uint64(-amount)
+	}
+	return uint64(amount)
+}
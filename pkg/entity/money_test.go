@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	money, err := ParseMoney("10.5", "usd")
+	assert.Nil(t, err)
+	assert.Equal(t, Money{Amount: 1050, Currency: "USD"}, money)
+	assert.Equal(t, "10.50", money.Decimal())
+
+	money, err = ParseMoney("-0.05", "EUR")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-5), money.Amount)
+	assert.Equal(t, "-0.05 EUR", money.String())
+
+	money, err = ParseMoney("1500", "JPY")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1500), money.Amount)
+	assert.Equal(t, "1500", money.Decimal())
+
+	money, err = ParseMoney("1.250", "KWD")
+	assert.Nil(t, err)
+	assert.Equal(t, "1.250", money.Decimal())
+
+	money, err = ParseMoney("0.30000", "USD")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(30), money.Amount)
+}
+
+func TestParseMoney_Invalid(t *testing.T) {
+	for _, amount := range []string{"", "abc", "1.", ".5", "1e3", "1,50", "0.1+0.2", "10.505", "99999999999999999999"} {
+		_, err := ParseMoney(amount, "USD")
+		assert.ErrorIs(t, err, ErrInvalidAmount, amount)
+	}
+
+	_, err := ParseMoney("1.5", "JPY")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = ParseMoney("1", "XYZ")
+	assert.ErrorIs(t, err, ErrInvalidCurrency)
+}
+
+func TestMoney_JSON(t *testing.T) {
+	data, err := json.Marshal(Money{Amount: 1005, Currency: "BRL"})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"amount": "10.05", "currency": "BRL"}`, string(data))
+
+	var money Money
+	assert.Nil(t, json.Unmarshal(data, &money))
+	assert.Equal(t, Money{Amount: 1005, Currency: "BRL"}, money)
+
+	assert.Nil(t, json.Unmarshal([]byte(`{"amount": 0.30, "currency": "EUR"}`), &money))
+	assert.Equal(t, Money{Amount: 30, Currency: "EUR"}, money)
+
+	assert.Nil(t, json.Unmarshal([]byte(`19.99`), &money))
+	assert.Equal(t, Money{Amount: 1999, Currency: DefaultCurrency}, money)
+
+	assert.Nil(t, json.Unmarshal([]byte(`"7"`), &money))
+	assert.Equal(t, Money{Amount: 700, Currency: DefaultCurrency}, money)
+
+	assert.ErrorIs(t, json.Unmarshal([]byte(`0.001`), &money), ErrInvalidAmount)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"amount": "1", "currency": "XYZ"}`), &money), ErrInvalidCurrency)
+}
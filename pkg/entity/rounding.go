@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"errors"
+	"math/big"
+)
+
+var ErrInvalidRounding = errors.New("invalid rounding")
+
+type RoundingMode string
+
+const (
+	RoundHalfUp   RoundingMode = "half_up"
+	RoundHalfEven RoundingMode = "half_even"
+	// RoundUp rounds away from zero
+	RoundUp RoundingMode = "up"
+	// RoundDown rounds towards zero
+	RoundDown RoundingMode = "down"
+)
+
+// Rounding rounds amounts of a currency to a multiple of Increment minor
+// units, e.g. 5 to round Swiss francs to 0.05.
+type Rounding struct {
+	Mode      RoundingMode
+	Increment int64
+}
+
+// DefaultRounding rounds half up to the minor unit.
+var DefaultRounding = Rounding{Mode: RoundHalfUp, Increment: 1}
+
+func (r Rounding) Validate() error {
+	if r.Increment < 1 {
+		return ErrInvalidRounding
+	}
+	switch r.Mode {
+	case RoundHalfUp, RoundHalfEven, RoundUp, RoundDown:
+		return nil
+	}
+	return ErrInvalidRounding
+}
+
+// Convert returns m in currency, multiplied by rate and rounded with rounding.
+func (m Money) Convert(currency string, rate *big.Rat, rounding Rounding) (Money, error) {
+	if err := rounding.Validate(); err != nil {
+		return Money{}, err
+	}
+	target, err := NewMoney(0, currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	value := new(big.Rat).SetInt64(m.Amount)
+	value.Mul(value, rate)
+	value.Mul(value, pow10(target.Exponent()-m.Exponent()))
+	value.Quo(value, new(big.Rat).SetInt64(rounding.Increment))
+
+	amount := round(value, rounding.Mode)
+	amount.Mul(amount, big.NewInt(rounding.Increment))
+	if !amount.IsInt64() {
+		return Money{}, ErrInvalidAmount
+	}
+	target.Amount = amount.Int64()
+	return target, nil
+}
+
+func round(value *big.Rat, mode RoundingMode) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	if remainder.Sign() == 0 || mode == RoundDown {
+		return quotient
+	}
+
+	away := big.NewInt(int64(value.Sign()))
+	if mode == RoundUp {
+		return quotient.Add(quotient, away)
+	}
+
+	twice := remainder.Abs(remainder)
+	twice.Lsh(twice, 1)
+	switch twice.Cmp(value.Denom()) {
+	case 1:
+		quotient.Add(quotient, away)
+	case 0:
+		if mode == RoundHalfUp || quotient.Bit(0) == 1 {
+			quotient.Add(quotient, away)
+		}
+	}
+	return quotient
+}
+
+func pow10(exponent int) *big.Rat {
+	power := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absolute(int64(exponent)))), nil)
+	if exponent < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), power)
+	}
+	return new(big.Rat).SetInt(power)
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+func TestMoney_Convert(t *testing.T) {
+	rate := big.NewRat(92, 100)
+
+	converted, err := Money{Amount: 1000, Currency: "USD"}.Convert("EUR", rate, DefaultRounding)
+	assert.Nil(t, err)
+	assert.Equal(t, Money{Amount: 920, Currency: "EUR"}, converted)
+
+	converted, err = Money{Amount: 1999, Currency: "USD"}.Convert("JPY", big.NewRat(15025, 100), DefaultRounding)
+	assert.Nil(t, err)
+	assert.Equal(t, Money{Amount: 3003, Currency: "JPY"}, converted)
+
+	converted, err = Money{Amount: 300, Currency: "JPY"}.Convert("USD", big.NewRat(100, 15025), DefaultRounding)
+	assert.Nil(t, err)
+	assert.Equal(t, Money{Amount: 200, Currency: "USD"}, converted)
+
+	converted, err = Money{Amount: 1234, Currency: "USD"}.Convert("CHF", big.NewRat(1, 1), Rounding{Mode: RoundHalfUp, Increment: 5})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1235), converted.Amount)
+
+	_, err = Money{Amount: 1, Currency: "USD"}.Convert("XYZ", rate, DefaultRounding)
+	assert.ErrorIs(t, err, ErrInvalidCurrency)
+
+	_, err = Money{Amount: 1, Currency: "USD"}.Convert("EUR", rate, Rounding{Mode: "sideways", Increment: 1})
+	assert.ErrorIs(t, err, ErrInvalidRounding)
+}
+
+func TestMoney_ConvertRoundingModes(t *testing.T) {
+	half := big.NewRat(1, 2)
+	for _, c := range []struct {
+		amount   int64
+		mode     RoundingMode
+		expected int64
+	}{
+		{5, RoundHalfUp, 3},
+		{3, RoundHalfUp, 2},
+		{-5, RoundHalfUp, -3},
+		{5, RoundHalfEven, 2},
+		{7, RoundHalfEven, 4},
+		{3, RoundUp, 2},
+		{-3, RoundUp, -2},
+		{3, RoundDown, 1},
+		{-3, RoundDown, -1},
+		{4, RoundDown, 2},
+	} {
+		converted, err := Money{Amount: c.amount, Currency: "USD"}.Convert("USD", half, Rounding{Mode: c.mode, Increment: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, c.expected, converted.Amount, "%d %s", c.amount, c.mode)
+	}
+}
@@ -0,0 +1,87 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	assert.Nil(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	img, err := Decode(encodePNG(t, 40, 20))
+	assert.Nil(t, err)
+	assert.Equal(t, "image/png", img.ContentType)
+	assert.Equal(t, 40, img.Bounds().Dx())
+	assert.Equal(t, ".png", Extension(img.ContentType))
+
+	var buf bytes.Buffer
+	assert.Nil(t, gif.Encode(&buf, image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.Black}), nil))
+	img, err = Decode(buf.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, "image/gif", img.ContentType)
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	_, err := Decode([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"))
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	_, err = Decode([]byte("GIF89a"))
+	assert.ErrorIs(t, err, ErrInvalidImage)
+
+	// a PNG header claiming a canvas far bigger than the file
+	data := encodePNG(t, 1, 1)
+	huge := append([]byte{}, data...)
+	copy(huge[16:24], []byte{0, 0, 0x40, 0, 0, 0, 0x40, 0})
+	binary.BigEndian.PutUint32(huge[29:33], crc32.ChecksumIEEE(huge[12:29]))
+	_, err = Decode(huge)
+	assert.ErrorIs(t, err, ErrTooManyPixels)
+}
+
+func TestThumbnail(t *testing.T) {
+	img, err := Decode(encodePNG(t, 400, 100))
+	assert.Nil(t, err)
+
+	thumb := Thumbnail(img, 200)
+	assert.Equal(t, image.Rect(0, 0, 200, 50), thumb.Bounds())
+	r, g, b, a := thumb.At(10, 10).RGBA()
+	assert.Equal(t, [4]uint32{0xffff, 0, 0, 0xffff}, [4]uint32{r, g, b, a})
+
+	tall := Thumbnail(image.NewRGBA(image.Rect(0, 0, 10, 1000)), 200)
+	assert.Equal(t, image.Rect(0, 0, 2, 200), tall.Bounds())
+
+	small := image.NewRGBA(image.Rect(0, 0, 50, 50))
+	assert.Same(t, small, Thumbnail(small, 200))
+}
+
+func TestEncode(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	var buf bytes.Buffer
+	contentType, err := Encode(&buf, img, "image/jpeg")
+	assert.Nil(t, err)
+	assert.Equal(t, "image/jpeg", contentType)
+	detected, err := Detect(buf.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, "image/jpeg", detected)
+
+	buf.Reset()
+	contentType, err = Encode(&buf, img, "image/gif")
+	assert.Nil(t, err)
+	assert.Equal(t, "image/png", contentType)
+}
@@ -1,7 +1,7 @@
 package utils
 
 import (
-	"github.com/SchunckLeonardo/go-expert-api/internal/entity"
+	"github.com/SchunckLeonardo/go-expert-api/internal/infra/database/migrations"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -11,7 +11,10 @@ import (
 func OpenDBConnection(t *testing.T) *gorm.DB {
 	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
 	assert.Nil(t, err)
-	err = db.AutoMigrate(&entity.Product{})
+
+	migrator, err := migrations.NewMigrator(db, migrations.All())
+	assert.Nil(t, err)
+	_, err = migrator.Up()
 	assert.Nil(t, err)
 
 	return db